@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/mattermost/chewbacca/internal/git"
+	"github.com/mattermost/chewbacca/internal/github"
+	"github.com/mattermost/chewbacca/internal/utils"
+
+	"github.com/pkg/errors"
+	logrus "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// addGitHubFlags adds the flags configuring the GitHub instance and credentials to the
+// command.
+func addGitHubFlags(command *cobra.Command) {
+	command.PersistentFlags().String("github-token", "", "The GitHub token to the bot be able to interact.")
+	command.PersistentFlags().Int64("github-app-id", 0, "The ID of the GitHub App to run as. When set, the installation tokens of the app are used instead of the GitHub token.")
+	command.PersistentFlags().String("github-app-private-key", "", "The path of the private key of the GitHub App.")
+	command.PersistentFlags().String("github-api-url", "", "The API URL of the GitHub Enterprise Server instance, like https://github.example.com/api/v3/. github.com is used when empty.")
+	command.PersistentFlags().String("github-upload-url", "", "The upload URL of the GitHub Enterprise Server instance. The API URL is used when empty.")
+	command.PersistentFlags().String("command-help-url", utils.DefaultCommandHelpURL, "The page listing the commands of the bot, linked in its comments.")
+	command.PersistentFlags().String("release-note-process-url", utils.DefaultReleaseNoteProcessURL, "The page describing the release note process, linked in the comments of the bot.")
+}
+
+// newGitHubClient creates the GitHub client from the flags added by addGitHubFlags, and
+// sets the links of the bot comments.
+func newGitHubClient(command *cobra.Command, gitHubSecrets []string, allowSHA1 bool, logger logrus.FieldLogger) (*github.GHClient, error) {
+	gitHubToken, _ := command.Flags().GetString("github-token")
+	gitHubAppID, _ := command.Flags().GetInt64("github-app-id")
+	gitHubAppPrivateKey, _ := command.Flags().GetString("github-app-private-key")
+	gitHubAPIURL, _ := command.Flags().GetString("github-api-url")
+	gitHubUploadURL, _ := command.Flags().GetString("github-upload-url")
+	commandHelpURL, _ := command.Flags().GetString("command-help-url")
+	releaseNoteProcessURL, _ := command.Flags().GetString("release-note-process-url")
+
+	utils.SetLinks(commandHelpURL, releaseNoteProcessURL)
+
+	endpoint := github.Endpoint{
+		APIURL:    gitHubAPIURL,
+		UploadURL: gitHubUploadURL,
+	}
+
+	if gitHubAppID != 0 {
+		privateKey, err := os.ReadFile(gitHubAppPrivateKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the GitHub App private key")
+		}
+
+		return github.NewGitHubAppConfig(gitHubAppID, privateKey, endpoint, gitHubSecrets, allowSHA1, logger)
+	}
+
+	if gitHubToken == "" {
+		return nil, errors.New("either --github-token or --github-app-id must be set")
+	}
+	return github.NewGitHubConfig(gitHubToken, endpoint, gitHubSecrets, allowSHA1, logger)
+}
+
+// newGitClient creates the git client authenticated with the tokens of the GitHub client.
+func newGitClient(gitHubClient *github.GHClient, committerName, committerEmail string, logger logrus.FieldLogger) *git.Client {
+	remoteURL := func(org, repo string) (string, error) {
+		token, err := gitHubClient.Token(org)
+		if err != nil {
+			return "", err
+		}
+		remote, err := url.Parse(fmt.Sprintf("%s/%s/%s.git", gitHubClient.WebURL(), org, repo))
+		if err != nil {
+			return "", errors.Wrap(err, "invalid repository URL")
+		}
+		remote.User = url.UserPassword("x-access-token", token)
+		return remote.String(), nil
+	}
+
+	return git.NewClient(remoteURL, committerName, committerEmail, logger.WithField("component", "git"))
+}
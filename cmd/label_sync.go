@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/api"
+	"github.com/mattermost/chewbacca/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addGitHubFlags(labelSyncCmd)
+	labelSyncCmd.Flags().String("config", "", "The path of the label manifest.")
+	labelSyncCmd.Flags().StringSlice("org", nil, "The organizations whose repositories labels are synchronized.")
+	labelSyncCmd.Flags().Bool("dry-run", false, "Whether to only report the label changes instead of applying them.")
+	labelSyncCmd.Flags().Bool("delete", false, "Whether to delete the labels which are not in the manifest.")
+	labelSyncCmd.Flags().Bool("json", false, "Whether to output the report in JSON.")
+	labelSyncCmd.MarkFlagRequired("config")
+	labelSyncCmd.MarkFlagRequired("org")
+
+	rootCmd.AddCommand(labelSyncCmd)
+}
+
+var labelSyncCmd = &cobra.Command{
+	Use:   "label-sync",
+	Short: "Create, update, rename and delete the labels of all the repositories of an organization to match a manifest.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		manifestPath, _ := command.Flags().GetString("config")
+		manifest, err := config.LoadLabelManifest(manifestPath)
+		if err != nil {
+			return err
+		}
+
+		gitHubClient, err := newGitHubClient(command, nil, false, logger)
+		if err != nil {
+			return err
+		}
+
+		apiContext := &api.Context{
+			GitHub: gitHubClient,
+			Config: config.NewStore(gitHubClient, time.Hour, logger),
+			Logger: logger,
+		}
+
+		orgs, _ := command.Flags().GetStringSlice("org")
+		dryRun, _ := command.Flags().GetBool("dry-run")
+		deleteUnknown, _ := command.Flags().GetBool("delete")
+		outputJSON, _ := command.Flags().GetBool("json")
+
+		var report []*api.LabelSyncAction
+		for _, org := range orgs {
+			actions, err := api.SyncLabels(apiContext, org, manifest, api.LabelSyncOptions{
+				DryRun: dryRun,
+				Delete: deleteUnknown,
+			})
+			report = append(report, actions...)
+			if err != nil {
+				return err
+			}
+		}
+
+		if outputJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report)
+		}
+
+		for _, action := range report {
+			fmt.Println(action.String())
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/api"
+	"github.com/mattermost/chewbacca/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addGitHubFlags(lifecycleCmd)
+	lifecycleCmd.Flags().StringSlice("repo", nil, "The repositories, as org/repo, whose inactive issues and PRs are checked.")
+	lifecycleCmd.Flags().Bool("dry-run", true, "Whether to only report the lifecycle actions instead of applying them.")
+	lifecycleCmd.Flags().Bool("json", false, "Whether to output the report in JSON.")
+	lifecycleCmd.MarkFlagRequired("repo")
+
+	rootCmd.AddCommand(lifecycleCmd)
+}
+
+var lifecycleCmd = &cobra.Command{
+	Use:   "lifecycle",
+	Short: "Mark the inactive issues and PRs as stale, rotten and then close them.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		gitHubClient, err := newGitHubClient(command, nil, false, logger)
+		if err != nil {
+			return err
+		}
+
+		apiContext := &api.Context{
+			GitHub: gitHubClient,
+			Config: config.NewStore(gitHubClient, time.Hour, logger),
+			Logger: logger,
+		}
+
+		repos, _ := command.Flags().GetStringSlice("repo")
+		dryRun, _ := command.Flags().GetBool("dry-run")
+		outputJSON, _ := command.Flags().GetBool("json")
+
+		var report []*api.LifecycleAction
+		for _, fullName := range repos {
+			org, repo, err := api.SplitRepo(fullName)
+			if err != nil {
+				return err
+			}
+
+			actions, err := api.RunLifecycle(apiContext, org, repo, dryRun, time.Now())
+			report = append(report, actions...)
+			if err != nil {
+				return err
+			}
+		}
+
+		if outputJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report)
+		}
+
+		for _, action := range report {
+			fmt.Println(action.String())
+		}
+
+		return nil
+	},
+}
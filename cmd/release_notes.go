@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/api"
+	"github.com/mattermost/chewbacca/internal/config"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addGitHubFlags(releaseNotesCmd)
+	releaseNotesCmd.Flags().String("repo", "", "The repository, as org/repo, whose release notes are generated.")
+	releaseNotesCmd.Flags().String("from", "", "The ref of the previous release, like v9.1.0.")
+	releaseNotesCmd.Flags().String("to", "", "The ref of the release, like v9.2.0.")
+	releaseNotesCmd.Flags().String("output", "markdown", "The output format, markdown or json.")
+	releaseNotesCmd.MarkFlagRequired("repo")
+	releaseNotesCmd.MarkFlagRequired("from")
+	releaseNotesCmd.MarkFlagRequired("to")
+
+	rootCmd.AddCommand(releaseNotesCmd)
+}
+
+var releaseNotesCmd = &cobra.Command{
+	Use:   "release-notes",
+	Short: "Generate the release notes of the PRs merged between two refs.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		output, _ := command.Flags().GetString("output")
+		if output != "markdown" && output != "json" {
+			return errors.Errorf("invalid output format %q, expected markdown or json", output)
+		}
+
+		fullName, _ := command.Flags().GetString("repo")
+		org, repo, err := api.SplitRepo(fullName)
+		if err != nil {
+			return err
+		}
+
+		gitHubClient, err := newGitHubClient(command, nil, false, logger)
+		if err != nil {
+			return err
+		}
+
+		apiContext := &api.Context{
+			GitHub: gitHubClient,
+			Config: config.NewStore(gitHubClient, time.Hour, logger),
+			Logger: logger,
+		}
+
+		from, _ := command.Flags().GetString("from")
+		to, _ := command.Flags().GetString("to")
+		notes, err := api.GenerateReleaseNotes(apiContext, org, repo, from, to)
+		if err != nil {
+			return err
+		}
+
+		if output == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(notes)
+		}
+
+		fmt.Print(notes.Markdown())
+		return nil
+	},
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/api"
+	"github.com/mattermost/chewbacca/internal/archive"
+	"github.com/mattermost/chewbacca/internal/config"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/pkg/errors"
+	logrus "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	replayModeLive   = "live"
+	replayModeDryRun = "dry-run"
+	replayModeFake   = "fake"
+)
+
+func init() {
+	addGitHubFlags(replayCmd)
+	replayCmd.Flags().String("mode", replayModeDryRun, "How the changes of the plugins are made: live on GitHub, recorded with the reads done on GitHub (dry-run), or recorded on a local fake GitHub seeded from the webhooks (fake).")
+	replayCmd.Flags().String("fake-config", "", "The path of the configuration file of the repositories in fake mode. The default configuration is used when empty.")
+	replayCmd.Flags().StringSlice("fake-members", nil, "The logins of the org members in fake mode.")
+	replayCmd.Flags().Bool("json", false, "Whether to output the recorded changes in JSON.")
+
+	rootCmd.AddCommand(replayCmd)
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <file|dir>...",
+	Short: "Replay archived webhooks through the plugins.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		var records []*archive.Record
+		for _, path := range args {
+			loaded, err := archive.Load(path)
+			if err != nil {
+				return err
+			}
+			records = append(records, loaded...)
+		}
+
+		mode, _ := command.Flags().GetString("mode")
+		recorder := api.NewRecorder(10000)
+		apiContext := &api.Context{
+			Plugins:  api.NewDefaultRegistry(),
+			Recorder: recorder,
+			Logger:   logger,
+		}
+
+		var fake *api.FakeGitHub
+		switch mode {
+		case replayModeLive, replayModeDryRun:
+			gitHubClient, err := newGitHubClient(command, nil, false, logger)
+			if err != nil {
+				return err
+			}
+			apiContext.GitHub = gitHubClient
+			apiContext.Config = config.NewStore(gitHubClient, time.Hour, logger)
+			apiContext.DryRun = mode == replayModeDryRun
+		case replayModeFake:
+			fake = api.NewFakeGitHub(recorder)
+			fakeConfig, _ := command.Flags().GetString("fake-config")
+			if fakeConfig != "" {
+				data, err := os.ReadFile(fakeConfig)
+				if err != nil {
+					return errors.Wrap(err, "failed to read the fake configuration")
+				}
+				fake.Files[config.FileName] = data
+			}
+			fakeMembers, _ := command.Flags().GetStringSlice("fake-members")
+			fake.Members = sets.New[string](fakeMembers...)
+			apiContext.GitHub = fake
+			apiContext.Config = config.NewStore(fake, time.Hour, logger)
+		default:
+			return errors.Errorf("invalid mode %q, expected %s, %s or %s", mode, replayModeLive, replayModeDryRun, replayModeFake)
+		}
+
+		var failed int
+		for _, record := range records {
+			eventType := record.EventType()
+			if eventType == model.EventTypePing {
+				continue
+			}
+
+			c := apiContext.Clone()
+			c.RequestID = model.NewID()
+			c.Logger = c.Logger.WithFields(logrus.Fields{
+				"request":  c.RequestID,
+				"event":    eventType,
+				"delivery": record.DeliveryID(),
+			})
+
+			if fake != nil {
+				fake.Observe(eventType, record.Body)
+			}
+			if _, err := api.ProcessEvent(c, eventType, record.Body, nil); err != nil {
+				c.Logger.WithError(err).Error("failed to replay the event")
+				failed++
+			}
+		}
+
+		outputJSON, _ := command.Flags().GetBool("json")
+		if outputJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(recorder.Actions("", "")); err != nil {
+				return err
+			}
+		} else {
+			for _, action := range recorder.Actions("", "") {
+				fmt.Println(action.String())
+			}
+		}
+
+		if failed > 0 {
+			return errors.Errorf("failed to replay %d of the %d events", failed, len(records))
+		}
+		return nil
+	},
+}
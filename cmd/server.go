@@ -9,7 +9,10 @@ import (
 	"time"
 
 	"github.com/mattermost/chewbacca/internal/api"
-	"github.com/mattermost/chewbacca/internal/github"
+	"github.com/mattermost/chewbacca/internal/archive"
+	"github.com/mattermost/chewbacca/internal/config"
+	"github.com/mattermost/chewbacca/internal/dedup"
+	"github.com/mattermost/chewbacca/internal/queue"
 	"github.com/mattermost/chewbacca/model"
 
 	"github.com/gorilla/mux"
@@ -23,8 +26,34 @@ func init() {
 	instanceID = model.NewID()
 
 	serverCmd.PersistentFlags().String("listen", ":8075", "The interface and port on which to listen.")
-	serverCmd.PersistentFlags().String("github-token", "", "The GitHub token to the bot be able to interact.")
-	serverCmd.PersistentFlags().String("github-secret", "", "The GitHub secret key to use to validate the request from github.")
+	addGitHubFlags(serverCmd)
+	serverCmd.PersistentFlags().StringArray("github-secret", nil, "The GitHub secret keys to use to validate the request from github. Can be repeated to rotate the secret, the values are not split on commas.")
+	serverCmd.PersistentFlags().Bool("allow-sha1-signature", false, "Whether to accept webhooks signed only with the deprecated SHA-1 X-Hub-Signature header.")
+	serverCmd.PersistentFlags().Duration("config-cache-ttl", 5*time.Minute, "How long the repository configuration files are cached.")
+	serverCmd.PersistentFlags().String("queue-path", "chewbacca.db", "The path of the database file holding the queued webhook events.")
+	serverCmd.PersistentFlags().Int("queue-workers", 4, "The number of webhook events processed concurrently.")
+	serverCmd.PersistentFlags().Int("queue-max-attempts", 5, "The number of attempts to process a webhook event before moving it to the dead letters.")
+	serverCmd.PersistentFlags().Duration("queue-retry-backoff", 30*time.Second, "The delay before retrying a failed webhook event, doubled on each attempt.")
+	serverCmd.PersistentFlags().Int("max-queue-depth", 1000, "The number of queued webhook events above which the server reports it is not ready.")
+	serverCmd.PersistentFlags().Duration("delivery-ttl", 24*time.Hour, "How long the received webhook delivery IDs are remembered to skip the redeliveries.")
+	serverCmd.PersistentFlags().Int("delivery-max-entries", 100000, "The maximum number of webhook delivery IDs remembered.")
+	serverCmd.PersistentFlags().StringSlice("lifecycle-repos", nil, "The repositories, as org/repo, whose inactive issues and PRs are marked as stale, rotten and then closed.")
+	serverCmd.PersistentFlags().Duration("lifecycle-interval", 6*time.Hour, "How often the lifecycle of the inactive issues and PRs is run.")
+	serverCmd.PersistentFlags().Bool("lifecycle-dry-run", false, "Whether to only log the lifecycle actions instead of applying them.")
+	serverCmd.PersistentFlags().String("label-sync-config", "", "The path of the label manifest the labels of the --label-sync-orgs repositories are synchronized with.")
+	serverCmd.PersistentFlags().StringSlice("label-sync-orgs", nil, "The organizations whose repositories labels are synchronized with the label manifest.")
+	serverCmd.PersistentFlags().Duration("label-sync-interval", 24*time.Hour, "How often the labels are synchronized.")
+	serverCmd.PersistentFlags().Bool("label-sync-delete", false, "Whether to delete the labels which are not in the label manifest.")
+	serverCmd.PersistentFlags().Bool("label-sync-dry-run", false, "Whether to only log the label changes instead of applying them.")
+	serverCmd.PersistentFlags().String("cherry-pick-fork", "", "The user or organization owning the forks the cherry-pick branches are pushed to. The branches are pushed to the repositories themselves when empty.")
+	serverCmd.PersistentFlags().String("git-committer-name", "chewbacca", "The name of the committer of the cherry-picks.")
+	serverCmd.PersistentFlags().String("git-committer-email", "chewbacca@mattermost.com", "The email of the committer of the cherry-picks.")
+	serverCmd.PersistentFlags().Bool("dry-run", false, "Whether to record the changes of the plugins, viewable with the admin endpoints, instead of making them on all the repositories.")
+	serverCmd.PersistentFlags().Int("dry-run-max-actions", 1000, "The number of changes recorded in dry-run mode kept for the admin endpoints.")
+	serverCmd.PersistentFlags().String("archive-dir", "", "The directory the raw webhooks are archived in, to replay them with the replay command. Nothing is archived when empty.")
+	serverCmd.PersistentFlags().Duration("archive-max-age", 7*24*time.Hour, "How long the archived webhooks are kept. They are kept forever when zero.")
+	serverCmd.PersistentFlags().Int("archive-max-files", 10000, "The number of archived webhooks kept, the oldest ones are removed first. There is no limit when zero.")
+	serverCmd.PersistentFlags().String("admin-token", "", "The bearer token protecting the admin endpoints. The admin endpoints are disabled when empty.")
 	serverCmd.PersistentFlags().Bool("debug", false, "Whether to output debug logs.")
 	serverCmd.PersistentFlags().Bool("machine-readable-logs", false, "Output the logs in machine readable format.")
 }
@@ -51,17 +80,105 @@ var serverCmd = &cobra.Command{
 			"debug": debug,
 		}).Info("Starting Chewbacca Server")
 
-		gitHubToken, _ := command.Flags().GetString("github-token")
-		gitHubSecret, _ := command.Flags().GetString("github-secret")
+		gitHubSecrets, _ := command.Flags().GetStringArray("github-secret")
+		allowSHA1, _ := command.Flags().GetBool("allow-sha1-signature")
 
-		gitHubClient := github.NewGitHubConfig(gitHubToken, gitHubSecret, logger)
+		gitHubClient, err := newGitHubClient(command, gitHubSecrets, allowSHA1, logger)
+		if err != nil {
+			return err
+		}
+
+		configCacheTTL, _ := command.Flags().GetDuration("config-cache-ttl")
+		configStore := config.NewStore(gitHubClient, configCacheTTL, logger)
+
+		deliveryTTL, _ := command.Flags().GetDuration("delivery-ttl")
+		deliveryMaxEntries, _ := command.Flags().GetInt("delivery-max-entries")
+
+		maxQueueDepth, _ := command.Flags().GetInt("max-queue-depth")
+		adminToken, _ := command.Flags().GetString("admin-token")
+		cherryPickFork, _ := command.Flags().GetString("cherry-pick-fork")
+		dryRun, _ := command.Flags().GetBool("dry-run")
+		dryRunMaxActions, _ := command.Flags().GetInt("dry-run-max-actions")
+		gitCommitterName, _ := command.Flags().GetString("git-committer-name")
+		gitCommitterEmail, _ := command.Flags().GetString("git-committer-email")
+		var webhookArchive api.WebhookArchive
+		archiveDir, _ := command.Flags().GetString("archive-dir")
+		if archiveDir != "" {
+			archiveMaxAge, _ := command.Flags().GetDuration("archive-max-age")
+			archiveMaxFiles, _ := command.Flags().GetInt("archive-max-files")
+			webhookArchive, err = archive.New(archiveDir, archive.Options{
+				MaxAge:   archiveMaxAge,
+				MaxFiles: archiveMaxFiles,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		apiContext := &api.Context{
+			GitHub:         gitHubClient,
+			Config:         configStore,
+			Deliveries:     dedup.NewStore(deliveryTTL, deliveryMaxEntries),
+			Archive:        webhookArchive,
+			Plugins:        api.NewDefaultRegistry(),
+			MaxQueueDepth:  maxQueueDepth,
+			AdminToken:     adminToken,
+			Git:            newGitClient(gitHubClient, gitCommitterName, gitCommitterEmail, logger),
+			CherryPickFork: cherryPickFork,
+			DryRun:         dryRun,
+			Recorder:       api.NewRecorder(dryRunMaxActions),
+			Logger:         logger,
+		}
+
+		queuePath, _ := command.Flags().GetString("queue-path")
+		queueWorkers, _ := command.Flags().GetInt("queue-workers")
+		queueMaxAttempts, _ := command.Flags().GetInt("queue-max-attempts")
+		queueRetryBackoff, _ := command.Flags().GetDuration("queue-retry-backoff")
+		eventQueue, err := queue.New(queuePath, api.NewJobHandler(apiContext), queue.Options{
+			Workers:     queueWorkers,
+			MaxAttempts: queueMaxAttempts,
+			Backoff:     queueRetryBackoff,
+			MaxBackoff:  time.Hour,
+		}, logger.WithField("component", "queue"))
+		if err != nil {
+			return err
+		}
+		apiContext.Queue = eventQueue
+		eventQueue.Start()
+
+		lifecycleRepos, _ := command.Flags().GetStringSlice("lifecycle-repos")
+		lifecycleInterval, _ := command.Flags().GetDuration("lifecycle-interval")
+		lifecycleDryRun, _ := command.Flags().GetBool("lifecycle-dry-run")
+		var lifecycleJob *api.LifecycleJob
+		if len(lifecycleRepos) > 0 {
+			lifecycleJob, err = api.NewLifecycleJob(apiContext, lifecycleRepos, lifecycleInterval, lifecycleDryRun)
+			if err != nil {
+				return err
+			}
+			lifecycleJob.Start()
+		}
+
+		labelSyncConfig, _ := command.Flags().GetString("label-sync-config")
+		labelSyncOrgs, _ := command.Flags().GetStringSlice("label-sync-orgs")
+		labelSyncInterval, _ := command.Flags().GetDuration("label-sync-interval")
+		labelSyncDelete, _ := command.Flags().GetBool("label-sync-delete")
+		labelSyncDryRun, _ := command.Flags().GetBool("label-sync-dry-run")
+		var labelSyncJob *api.LabelSyncJob
+		if labelSyncConfig != "" && len(labelSyncOrgs) > 0 {
+			manifest, err := config.LoadLabelManifest(labelSyncConfig)
+			if err != nil {
+				return err
+			}
+			labelSyncJob = api.NewLabelSyncJob(apiContext, labelSyncOrgs, manifest, labelSyncInterval, api.LabelSyncOptions{
+				DryRun: labelSyncDryRun,
+				Delete: labelSyncDelete,
+			})
+			labelSyncJob.Start()
+		}
 
 		router := mux.NewRouter()
 
-		api.Register(router, &api.Context{
-			GitHub: gitHubClient,
-			Logger: logger,
-		})
+		api.Register(router, apiContext)
 
 		listen, _ := command.Flags().GetString("listen")
 		srv := &http.Server{
@@ -95,6 +212,17 @@ var serverCmd = &cobra.Command{
 		defer cancel()
 		srv.Shutdown(ctx)
 
+		if lifecycleJob != nil {
+			lifecycleJob.Stop()
+		}
+		if labelSyncJob != nil {
+			labelSyncJob.Stop()
+		}
+
+		if err := eventQueue.Stop(); err != nil {
+			logger.WithError(err).Error("Failed to stop the queue")
+		}
+
 		return nil
 	},
 }
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/mattermost/chewbacca/internal/queue"
+
+	"github.com/gorilla/mux"
+)
+
+// initAdmin registers the admin endpoints on the given router when an admin token
+// is configured.
+func initAdmin(apiRouter *mux.Router, context *Context) {
+	if context.AdminToken == "" {
+		return
+	}
+
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, requireAdminToken(handler))
+	}
+
+	adminRouter := apiRouter.PathPrefix("/admin").Subrouter()
+	adminRouter.Handle("/queue/dead_letters", addContext(handleGetDeadLetters)).Methods("GET")
+	adminRouter.Handle("/queue/dead_letters/{id:[0-9]+}/retry", addContext(handleRetryDeadLetter)).Methods("POST")
+	adminRouter.Handle("/dry_run/actions", addContext(handleGetDryRunActions)).Methods("GET")
+}
+
+// requireAdminToken rejects the requests without the admin token as bearer token.
+func requireAdminToken(handler contextHandlerFunc) contextHandlerFunc {
+	return func(c *Context, w http.ResponseWriter, r *http.Request) {
+		expected := []byte("Bearer " + c.AdminToken)
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		handler(c, w, r)
+	}
+}
+
+// handleGetDeadLetters responds to GET /api/admin/queue/dead_letters, listing the
+// events that failed too many times.
+func handleGetDeadLetters(c *Context, w http.ResponseWriter, r *http.Request) {
+	if c.Queue == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	jobs, err := c.Queue.DeadLetters()
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to list the dead letters")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if jobs == nil {
+		jobs = []*queue.Job{}
+	}
+
+	outputJSON(c, w, jobs)
+}
+
+// handleRetryDeadLetter responds to POST /api/admin/queue/dead_letters/{id}/retry,
+// queuing the event again. The corrupted jobs are rejected with a conflict.
+func handleRetryDeadLetter(c *Context, w http.ResponseWriter, r *http.Request) {
+	if c.Queue == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = c.Queue.RetryDeadLetter(id)
+	if err == queue.ErrJobNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err == queue.ErrJobCorrupted {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to retry the dead letter")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleGetDryRunActions responds to GET /api/admin/dry_run/actions, listing the
+// changes recorded in dry-run mode. The optional org and repo query parameters filter
+// the changes of an organization or repository.
+func handleGetDryRunActions(c *Context, w http.ResponseWriter, r *http.Request) {
+	if c.Recorder == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	outputJSON(c, w, c.Recorder.Actions(query.Get("org"), query.Get("repo")))
+}
+
+func outputJSON(c *Context, w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		c.Logger.WithError(err).Error("failed to encode the response")
+	}
+}
@@ -4,13 +4,17 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // Register registers the API endpoints on the given router.
 func Register(rootRouter *mux.Router, context *Context) {
 
 	apiRouter := rootRouter.PathPrefix("/api").Subrouter()
+	rootRouter.Handle("/metrics", promhttp.Handler()).Methods("GET")
+	initHealth(rootRouter, context)
 	rootRouter.PathPrefix("/").Handler(http.FileServer(http.Dir("./static/")))
 
 	initGitHubWebhook(apiRouter, context)
+	initAdmin(apiRouter, context)
 }
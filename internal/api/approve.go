@@ -0,0 +1,165 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mattermost/chewbacca/internal/utils"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const approvedLabel = "approved"
+
+var (
+	approveRe       = regexp.MustCompile(`(?mi)^/approve\s*$`)
+	approveCancelRe = regexp.MustCompile(`(?mi)^/approve\s+cancel\s*$`)
+)
+
+// approvePlugin handles the /approve and /approve cancel commands. When the changed
+// files have OWNERS files, the approved label is only set once an approver of each
+// changed file approved the PR.
+type approvePlugin struct{}
+
+func (p *approvePlugin) Name() string {
+	return "approve"
+}
+
+func (p *approvePlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypePullRequest:  {model.PullRequestActionSynchronize},
+		model.EventTypeIssueComment: {model.IssueCommentActionCreated},
+	}
+}
+
+func (p *approvePlugin) Handle(c *Context, e *Event) error {
+	if e.PullRequest != nil {
+		return handleApproveSynchronize(c, e)
+	}
+	if !e.IsPullRequest {
+		return nil
+	}
+
+	comment := e.IssueComment.GetComment()
+	body := comment.GetBody()
+
+	var wantApproved bool
+	switch {
+	case approveCancelRe.MatchString(body):
+		wantApproved = false
+	case approveRe.MatchString(body):
+		wantApproved = true
+	default:
+		return nil
+	}
+
+	pr, err := c.GitHub.GetPullRequest(e.Org, e.Repo, e.Number)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the PR#%d", e.Number)
+	}
+	approval, err := getOwnersApproval(c, e.Org, e.Repo, pr, comment)
+	if err != nil {
+		return err
+	}
+	if approval != nil {
+		return handleOwnersApprove(c, e, approval, wantApproved)
+	}
+
+	isMember, err := c.GitHub.IsMember(e.Org, comment.GetUser().GetLogin())
+	if err != nil {
+		return errors.Wrap(err, "failed to get the membership")
+	}
+	if !isMember {
+		c.Logger.Info("not member")
+		return c.GitHub.CreateComment(e.Org, e.Repo, e.Number, utils.FormatICResponse(comment, "only the org members can approve PRs."))
+	}
+
+	hasApproved := utils.HasLabel(approvedLabel, e.IssueComment.GetIssue().Labels)
+	if wantApproved && !hasApproved {
+		c.Logger.Info("adding approved label")
+		return c.GitHub.AddLabels(e.Org, e.Repo, e.Number, []string{approvedLabel})
+	}
+	if !wantApproved && hasApproved {
+		c.Logger.Info("removing approved label")
+		return c.GitHub.RemoveLabel(e.Org, e.Repo, e.Number, approvedLabel)
+	}
+
+	return nil
+}
+
+// handleOwnersApprove sets the approved label according to the approval of the OWNERS
+// of the changed files.
+func handleOwnersApprove(c *Context, e *Event, approval *ownersApproval, wantApproved bool) error {
+	comment := e.IssueComment.GetComment()
+	commenter := utils.NormLogin(comment.GetUser().GetLogin())
+
+	isApprover := false
+	for _, file := range approval.Files {
+		if approval.Owners.Approvers(file).Has(commenter) {
+			isApprover = true
+			break
+		}
+	}
+	if !isApprover {
+		c.Logger.Info("not an approver")
+		return c.GitHub.CreateComment(e.Org, e.Repo, e.Number, utils.FormatICResponse(comment, "only the approvers listed in the OWNERS files of the changed files can approve this PR."))
+	}
+
+	if err := syncApprovedLabel(c, e.Org, e.Repo, e.Number, e.IssueComment.GetIssue().Labels, approval); err != nil {
+		return err
+	}
+
+	if wantApproved && !approval.Approved() {
+		return c.GitHub.CreateComment(e.Org, e.Repo, e.Number, utils.FormatICResponse(comment, formatUnapproved(approval)))
+	}
+
+	return nil
+}
+
+// handleApproveSynchronize removes the approved label when the new commits change
+// files which are not approved yet.
+func handleApproveSynchronize(c *Context, e *Event) error {
+	pr := e.PullRequest.GetPullRequest()
+	if !utils.HasLabel(approvedLabel, pr.Labels) {
+		return nil
+	}
+
+	approval, err := getOwnersApproval(c, e.Org, e.Repo, pr, nil)
+	if err != nil {
+		return err
+	}
+	if approval == nil {
+		return nil
+	}
+
+	return syncApprovedLabel(c, e.Org, e.Repo, e.Number, pr.Labels, approval)
+}
+
+// syncApprovedLabel sets the approved label when all the changed files are approved
+// and removes it otherwise.
+func syncApprovedLabel(c *Context, org, repo string, number int, labels []*github.Label, approval *ownersApproval) error {
+	hasApproved := utils.HasLabel(approvedLabel, labels)
+	if approval.Approved() && !hasApproved {
+		c.Logger.Info("adding approved label")
+		return c.GitHub.AddLabels(org, repo, number, []string{approvedLabel})
+	}
+	if !approval.Approved() && hasApproved {
+		c.Logger.Info("removing approved label")
+		return c.GitHub.RemoveLabel(org, repo, number, approvedLabel)
+	}
+	return nil
+}
+
+// formatUnapproved lists the files still needing an approval and their approvers.
+func formatUnapproved(approval *ownersApproval) string {
+	var b strings.Builder
+	b.WriteString("the PR still needs the approval of the OWNERS of these files:\n\n")
+	for _, file := range approval.Unapproved {
+		fmt.Fprintf(&b, "- `%s`: %s\n", file, formatLogins(sets.List(approval.Owners.Approvers(file))))
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"github.com/mattermost/chewbacca/internal/utils"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// areaPlugin labels the pull requests with the area labels matching their changed files.
+type areaPlugin struct{}
+
+func (p *areaPlugin) Name() string {
+	return "area"
+}
+
+func (p *areaPlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypePullRequest: {
+			model.PullRequestActionOpened,
+			model.PullRequestActionReopened,
+			model.PullRequestActionSynchronize,
+		},
+	}
+}
+
+func (p *areaPlugin) Handle(c *Context, e *Event) error {
+	cfg := c.RepoConfig(e.Org, e.Repo)
+	if len(cfg.AreaLabels) == 0 {
+		return nil
+	}
+
+	files, err := c.GitHub.ListPullRequestFiles(e.Org, e.Repo, e.Number)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the files of PR#%d", e.Number)
+	}
+
+	matching := sets.New[string]()
+	for _, file := range files {
+		matching.Insert(cfg.FileAreaLabels(file.GetFilename())...)
+	}
+
+	labels, err := c.GitHub.GetIssueLabels(e.Org, e.Repo, e.Number)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the labels of PR#%d", e.Number)
+	}
+	currentLabels := utils.LabelsSet(labels)
+
+	if missing := matching.Difference(currentLabels); missing.Len() > 0 {
+		c.Logger.Infof("adding the area labels %v", sets.List(missing))
+		if err := c.GitHub.AddLabels(e.Org, e.Repo, e.Number, sets.List(missing)); err != nil {
+			return errors.Wrap(err, "failed to add the area labels")
+		}
+	}
+
+	if !cfg.RemoveStaleAreaLabels {
+		return nil
+	}
+
+	areaLabels := sets.New[string]()
+	for _, area := range cfg.AreaLabels {
+		areaLabels.Insert(area.Label)
+	}
+	for _, label := range sets.List(currentLabels.Intersection(areaLabels).Difference(matching)) {
+		c.Logger.Infof("removing the stale area label %s", label)
+		if err := c.GitHub.RemoveLabel(e.Org, e.Repo, e.Number, label); err != nil {
+			return errors.Wrapf(err, "failed to remove the %s label", label)
+		}
+	}
+
+	return nil
+}
@@ -2,24 +2,96 @@ package api
 
 import (
 	"fmt"
+	"path"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/mattermost/chewbacca/internal/config"
 	"github.com/mattermost/chewbacca/internal/utils"
+	"github.com/mattermost/chewbacca/model"
 
+	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 const (
-	doNotMerge                  = "do-not-merge"
-	doNotMergeAwaitingPR        = "do-not-merge/awaiting-PR"
-	doNotMergeAwaitingSubmitter = "do-not-merge/awaiting-submitter-action"
-	doNotMergeWIP               = "do-not-merge/work-in-progress"
-	wip                         = "WIP"
-	releaseNoteLabelNeeded      = "do-not-merge/release-note-label-needed"
+	// blockerName is the name of the check run and the context of the status.
+	blockerName = "blocker"
+	// rerunActionIdentifier identifies the check run action re-evaluating the PR.
+	rerunActionIdentifier = "rerun"
+
+	// maxStatusDescription is the maximum number of characters of a commit status
+	// description.
+	maxStatusDescription = 140
+	// maxCheckRunAnnotations is the maximum number of annotations per check run request.
+	maxCheckRunAnnotations = 50
 )
 
+// blockingLabelMessages explains how to clear the blocking labels which aren't
+// simply removed by hand.
+var blockingLabelMessages = map[string]string{
+	ReleaseNoteLabelNeeded:    "Add a release-note block to the PR description, or comment `/release-note-none` if the PR doesn't need a release note.",
+	releaseNoteActionRequired: "The release note requires an action from the users. Remove the label once the required action is documented.",
+	needsRebaseLabel:          "The PR has conflicts with its base branch. Rebase it to resolve them.",
+	holdLabel:                 "Comment `/hold cancel` to release the hold.",
+}
+
+// blockReason is a reason preventing a pull request from being merged.
+type blockReason struct {
+	// Rule is the name of the rule blocking the merge.
+	Rule string
+	// Label is the label blocking the merge, if any.
+	Label string
+	// Message explains how to clear the blocker.
+	Message string
+	// Status replaces the label or rule in the commit status description, when set.
+	Status string
+	// Annotations point to the files related to the blocker.
+	Annotations []*github.CheckRunAnnotation
+}
+
+// blockerPlugin sets the merge blocker status of the pull requests.
+type blockerPlugin struct{}
+
+func (p *blockerPlugin) Name() string {
+	return blockerName
+}
+
+func (p *blockerPlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypePullRequest:  nil,
+		model.EventTypeIssueComment: nil,
+		model.EventTypeCheckRun:     {model.CheckRunActionRerequested, model.CheckRunActionRequestedAction},
+	}
+}
+
+func (p *blockerPlugin) Handle(c *Context, e *Event) error {
+	if e.CheckRun != nil && !isBlockerRerun(e.CheckRun) {
+		return nil
+	}
+	if !e.IsPullRequest {
+		return nil
+	}
+	return checkBlockStatus(c, e.Org, e.Repo, e.Number)
+}
+
+// isBlockerRerun returns whether the check run event asks to re-evaluate the blocker.
+func isBlockerRerun(event *github.CheckRunEvent) bool {
+	if event.GetCheckRun().GetName() != blockerName {
+		return false
+	}
+	if event.GetAction() == model.CheckRunActionRequestedAction {
+		return event.GetRequestedAction().Identifier == rerunActionIdentifier
+	}
+	return true
+}
+
 // checkBlockStatus checks if need to block the PR to be merged
-func checkBlockStatus(c *Context, org, repo string, number int) {
+func checkBlockStatus(c *Context, org, repo string, number int) error {
 	c.Logger = c.Logger.WithFields(log.Fields{
 		"number": number,
 		"org":    org,
@@ -29,40 +101,164 @@ func checkBlockStatus(c *Context, org, repo string, number int) {
 
 	pr, err := c.GitHub.GetPullRequest(org, repo, number)
 	if err != nil {
-		c.Logger.WithError(err).Errorf("failed to get the PR#%d", number)
-		return
+		return errors.Wrapf(err, "failed to get the PR#%d", number)
 	}
 
 	if pr.GetState() == "closed" {
-		return
+		return nil
 	}
 
 	labels, err := c.GitHub.GetIssueLabels(org, repo, number)
 	if err != nil {
-		c.Logger.WithError(err).Errorf("failed to list labels on PR #%d", number)
+		return errors.Wrapf(err, "failed to list the labels of PR#%d", number)
 	}
 
-	var mergeLabels []string
-	if utils.HasLabel(doNotMerge, labels) {
-		mergeLabels = append(mergeLabels, doNotMerge)
+	cfg := c.RepoConfig(org, repo)
+	reasons := evaluateBlockRules(cfg, pr, labels)
+
+	// The PRs changing files with OWNERS need the approval of their approvers.
+	if cfg.PluginEnabled("approve") {
+		approval, err := getOwnersApproval(c, org, repo, pr, nil)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the approval of PR#%d", number)
+		}
+		if approval != nil && !approval.Approved() {
+			reasons = append(reasons, ownersApprovalReason(approval))
+		}
 	}
-	if utils.HasLabel(doNotMergeAwaitingPR, labels) {
-		mergeLabels = append(mergeLabels, doNotMergeAwaitingPR)
+	describeHold(c, org, repo, number, reasons)
+
+	if c.GitHub.SupportsChecks() {
+		err = publishBlockerCheckRun(c, org, repo, buildBlockerCheckRun(pr.GetHead().GetSHA(), reasons))
+		if err != nil {
+			return errors.Wrapf(err, "failed to publish the check run PR#%d", number)
+		}
+		return nil
 	}
-	if utils.HasLabel(doNotMergeAwaitingSubmitter, labels) {
-		mergeLabels = append(mergeLabels, doNotMergeAwaitingPR)
+
+	state, desc := buildBlockerStatus(reasons)
+	err = c.GitHub.SetStatus(org, repo, pr.GetHead().GetSHA(), state, desc)
+	if err != nil {
+		return errors.Wrapf(err, "failed to set the status PR#%d", number)
+	}
+
+	return nil
+}
+
+// evaluateBlockRules returns the reasons blocking the PR from being merged according
+// to the rules of the configuration.
+func evaluateBlockRules(cfg *config.RepoConfig, pr *github.PullRequest, labels []*github.Label) []blockReason {
+	var reasons []blockReason
+	blockingLabels := sets.New[string]()
+	addLabel := func(rule config.BlockRule, label string) {
+		if blockingLabels.Has(strings.ToLower(label)) {
+			return
+		}
+		blockingLabels.Insert(strings.ToLower(label))
+		reasons = append(reasons, blockReason{
+			Rule:    rule.Name,
+			Label:   label,
+			Message: ruleMessage(rule, blockingLabelMessage(label)),
+		})
+	}
+	add := func(rule config.BlockRule, defaultMessage string) {
+		reasons = append(reasons, blockReason{Rule: rule.Name, Message: ruleMessage(rule, defaultMessage)})
+	}
+
+	for _, rule := range cfg.BlockRules() {
+		if len(rule.Branches) > 0 && !matchesAnyPattern(rule.Branches, pr.GetBase().GetRef()) {
+			continue
+		}
+
+		for _, label := range rule.Labels {
+			if utils.HasLabel(label, labels) {
+				addLabel(rule, label)
+			}
+		}
+		for _, label := range labels {
+			if matchesAnyPattern(rule.LabelPatterns, label.GetName()) {
+				addLabel(rule, label.GetName())
+			}
+		}
+		for _, label := range rule.RequiredLabels {
+			if !utils.HasLabel(label, labels) {
+				add(rule, fmt.Sprintf("Add the `%s` label.", label))
+			}
+		}
+		if rule.Draft && pr.GetDraft() {
+			add(rule, "The PR is a draft. Mark it as ready for review when it can be merged.")
+		}
+		for _, prefix := range rule.TitlePrefixes {
+			if hasTitlePrefix(pr.GetTitle(), prefix) {
+				add(rule, fmt.Sprintf("The title starts with `%s`. Remove it when the PR can be merged.", prefix))
+				break
+			}
+		}
+		if rule.MissingMilestone && pr.Milestone == nil {
+			add(rule, fmt.Sprintf("Set a milestone on the PR, it is required for PRs targeting `%s`.", pr.GetBase().GetRef()))
+		}
+		if rule.ConventionalTitle {
+			if msg := checkConventionalTitle(cfg, pr.GetTitle()); msg != "" {
+				add(rule, msg)
+			}
+		}
+	}
+
+	return reasons
+}
+
+func ruleMessage(rule config.BlockRule, defaultMessage string) string {
+	if rule.Message != "" {
+		return rule.Message
 	}
-	if utils.HasLabel(doNotMergeWIP, labels) {
-		mergeLabels = append(mergeLabels, doNotMergeWIP)
+	return defaultMessage
+}
+
+// matchesAnyPattern returns whether the name matches one of the patterns, ignoring case.
+func matchesAnyPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(name)); matched {
+			return true
+		}
 	}
-	if utils.HasLabel(releaseNoteLabelNeeded, labels) {
-		mergeLabels = append(mergeLabels, releaseNoteLabelNeeded)
+	return false
+}
+
+// hasTitlePrefix returns whether the title starts with the prefix, ignoring case. A
+// prefix ending with a letter or digit must be followed by a word boundary, so `WIP`
+// doesn't match "Wipe the caches".
+func hasTitlePrefix(title, prefix string) bool {
+	if prefix == "" || len(title) < len(prefix) || !strings.EqualFold(title[:len(prefix)], prefix) {
+		return false
 	}
-	if utils.HasLabel(releaseNoteActionRequired, labels) {
-		mergeLabels = append(mergeLabels, releaseNoteActionRequired)
+	last, _ := utf8.DecodeLastRuneInString(prefix)
+	next, _ := utf8.DecodeRuneInString(title[len(prefix):])
+	return !isAlphanumeric(last) || len(title) == len(prefix) || !isAlphanumeric(next)
+}
+
+func isAlphanumeric(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func blockingLabelMessage(label string) string {
+	if msg, ok := blockingLabelMessages[label]; ok {
+		return msg
 	}
-	if utils.HasLabel(wip, labels) {
-		mergeLabels = append(mergeLabels, wip)
+	return fmt.Sprintf("Remove the `%s` label when the PR is ready to be merged.", label)
+}
+
+// buildBlockerStatus returns the state and description of the commit status, used
+// when check runs are not supported.
+func buildBlockerStatus(reasons []blockReason) (string, string) {
+	var statuses, mergeLabels, rules []string
+	for _, reason := range reasons {
+		if reason.Status != "" {
+			statuses = append(statuses, reason.Status)
+		} else if reason.Label != "" {
+			mergeLabels = append(mergeLabels, reason.Label)
+		} else if len(rules) == 0 || rules[len(rules)-1] != reason.Rule {
+			rules = append(rules, reason.Rule)
+		}
 	}
 
 	var desc string
@@ -71,13 +267,100 @@ func checkBlockStatus(c *Context, org, repo string, number int) {
 		desc = fmt.Sprintf(" Should not have %s label.", mergeLabels[0])
 	} else if len(mergeLabels) > 1 {
 		desc = fmt.Sprintf(" Should not have %s labels.", strings.Join(mergeLabels, ", "))
-	} else {
+	} else if len(reasons) == 0 {
 		desc = "Merged allowed."
 		state = "success"
 	}
+	if len(rules) > 0 {
+		desc += fmt.Sprintf(" Blocked by %s.", strings.Join(rules, ", "))
+	}
+	if len(statuses) > 0 {
+		desc = strings.Join(statuses, " ") + desc
+	}
 
-	err = c.GitHub.SetStatus(org, repo, pr.GetHead().GetSHA(), state, desc)
+	// The description is truncated on a character boundary, to stay valid UTF-8.
+	if utf8.RuneCountInString(desc) > maxStatusDescription {
+		desc = string([]rune(desc)[:maxStatusDescription-3]) + "..."
+	}
+
+	return state, desc
+}
+
+// buildBlockerCheckRun returns the check run listing the reasons blocking the merge.
+func buildBlockerCheckRun(sha string, reasons []blockReason) github.CreateCheckRunOptions {
+	checkRun := github.CreateCheckRunOptions{
+		Name:    blockerName,
+		HeadSHA: sha,
+		Actions: []*github.CheckRunAction{{
+			Label:       "Re-run",
+			Description: "Re-evaluate the merge blockers",
+			Identifier:  rerunActionIdentifier,
+		}},
+	}
+
+	if len(reasons) == 0 {
+		checkRun.Status = github.String("completed")
+		checkRun.Conclusion = github.String("success")
+		checkRun.CompletedAt = &github.Timestamp{Time: time.Now()}
+		checkRun.Output = &github.CheckRunOutput{
+			Title:   github.String("Merge allowed"),
+			Summary: github.String("Nothing is blocking this PR from being merged."),
+		}
+		return checkRun
+	}
+
+	title := "Merge blocked by 1 reason"
+	if len(reasons) > 1 {
+		title = fmt.Sprintf("Merge blocked by %d reasons", len(reasons))
+	}
+
+	var summary strings.Builder
+	summary.WriteString("This PR can't be merged yet:\n\n")
+	var annotations []*github.CheckRunAnnotation
+	for _, reason := range reasons {
+		if reason.Label != "" {
+			fmt.Fprintf(&summary, "- **`%s`**: %s\n", reason.Label, reason.Message)
+		} else if reason.Rule != "" {
+			fmt.Fprintf(&summary, "- **%s**: %s\n", reason.Rule, reason.Message)
+		} else {
+			fmt.Fprintf(&summary, "- %s\n", reason.Message)
+		}
+		annotations = append(annotations, reason.Annotations...)
+	}
+	if len(annotations) > maxCheckRunAnnotations {
+		annotations = annotations[:maxCheckRunAnnotations]
+	}
+
+	// The run is completed so it can be re-run from GitHub, and fails to block the merge
+	// when the check is required.
+	checkRun.Status = github.String("completed")
+	checkRun.Conclusion = github.String("failure")
+	checkRun.CompletedAt = &github.Timestamp{Time: time.Now()}
+	checkRun.Output = &github.CheckRunOutput{
+		Title:       github.String(title),
+		Summary:     github.String(summary.String()),
+		Annotations: annotations,
+	}
+	return checkRun
+}
+
+// publishBlockerCheckRun updates the blocker check run of the commit, creating it the
+// first time, so each evaluation doesn't add a new run.
+func publishBlockerCheckRun(c *Context, org, repo string, checkRun github.CreateCheckRunOptions) error {
+	existing, err := c.GitHub.GetCheckRun(org, repo, checkRun.HeadSHA, checkRun.Name)
 	if err != nil {
-		c.Logger.WithError(err).Errorf("failed to set the status PR#%d", number)
+		return err
+	}
+	if existing == nil {
+		return c.GitHub.CreateCheckRun(org, repo, checkRun)
 	}
+
+	return c.GitHub.UpdateCheckRun(org, repo, existing.GetID(), github.UpdateCheckRunOptions{
+		Name:        checkRun.Name,
+		Status:      checkRun.Status,
+		Conclusion:  checkRun.Conclusion,
+		CompletedAt: checkRun.CompletedAt,
+		Output:      checkRun.Output,
+		Actions:     checkRun.Actions,
+	})
 }
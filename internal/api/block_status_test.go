@@ -0,0 +1,177 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mattermost/chewbacca/internal/config"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/google/go-github/v31/github"
+)
+
+func TestEvaluateBlockRules(t *testing.T) {
+	rules := []config.BlockRule{
+		{Name: "do-not-merge", Labels: []string{"do-not-merge"}},
+		{Name: "do-not-merge/*", LabelPatterns: []string{"do-not-merge/*"}, Message: "Blocked by a do-not-merge label."},
+		{Name: "wip", Draft: true, TitlePrefixes: []string{"WIP", "[WIP]"}},
+		{Name: "milestone", Branches: []string{"release-*"}, MissingMilestone: true},
+		{Name: "approved", RequiredLabels: []string{"approved"}},
+		{Name: "title", Branches: []string{"main"}, ConventionalTitle: true},
+	}
+	cfg := &config.RepoConfig{Blockers: rules, TitleTypes: config.Default().TitleTypes}
+	labels := func(names ...string) []*github.Label {
+		var labels []*github.Label
+		for _, name := range names {
+			labels = append(labels, &github.Label{Name: github.String(name)})
+		}
+		return labels
+	}
+
+	testCases := []struct {
+		name     string
+		pr       *github.PullRequest
+		labels   []*github.Label
+		expected []string
+	}{
+		{
+			name:     "mergeable",
+			pr:       &github.PullRequest{Title: github.String("Fix the bug"), Base: &github.PullRequestBranch{Ref: github.String("master")}},
+			labels:   labels("approved"),
+			expected: nil,
+		},
+		{
+			name:     "blocking labels",
+			pr:       &github.PullRequest{Title: github.String("Fix the bug"), Base: &github.PullRequestBranch{Ref: github.String("master")}},
+			labels:   labels("approved", "do-not-merge", "do-not-merge/awaiting-PR", "kind/bug"),
+			expected: []string{"do-not-merge", "do-not-merge/*"},
+		},
+		{
+			name:     "draft with WIP title",
+			pr:       &github.PullRequest{Title: github.String("wip: fix the bug"), Draft: github.Bool(true), Base: &github.PullRequestBranch{Ref: github.String("master")}},
+			labels:   labels("approved"),
+			expected: []string{"wip", "wip"},
+		},
+		{
+			name:     "title starting with a word prefixed by WIP",
+			pr:       &github.PullRequest{Title: github.String("Wipe the stale caches"), Base: &github.PullRequestBranch{Ref: github.String("master")}},
+			labels:   labels("approved"),
+			expected: nil,
+		},
+		{
+			name:     "title equal to WIP",
+			pr:       &github.PullRequest{Title: github.String("WIP"), Base: &github.PullRequestBranch{Ref: github.String("master")}},
+			labels:   labels("approved"),
+			expected: []string{"wip"},
+		},
+		{
+			name:     "missing milestone on release branch",
+			pr:       &github.PullRequest{Title: github.String("Fix the bug"), Base: &github.PullRequestBranch{Ref: github.String("release-9.1")}},
+			labels:   labels(),
+			expected: []string{"milestone", "approved"},
+		},
+		{
+			name:     "conventional title",
+			pr:       &github.PullRequest{Title: github.String("feat(api)!: remove the v1 endpoints"), Base: &github.PullRequestBranch{Ref: github.String("main")}},
+			labels:   labels("approved"),
+			expected: nil,
+		},
+		{
+			name:     "unknown title type",
+			pr:       &github.PullRequest{Title: github.String("feature: add an endpoint"), Base: &github.PullRequestBranch{Ref: github.String("main")}},
+			labels:   labels("approved"),
+			expected: []string{"title"},
+		},
+		{
+			name:     "milestone set on release branch",
+			pr:       &github.PullRequest{Title: github.String("Fix the bug"), Milestone: &github.Milestone{}, Base: &github.PullRequestBranch{Ref: github.String("release-9.1")}},
+			labels:   labels("approved"),
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reasons := evaluateBlockRules(cfg, tc.pr, tc.labels)
+			if len(reasons) != len(tc.expected) {
+				t.Fatalf("expected %d reasons, got %v", len(tc.expected), reasons)
+			}
+			for i, reason := range reasons {
+				if reason.Rule != tc.expected[i] {
+					t.Errorf("expected the rule %s, got %s", tc.expected[i], reason.Rule)
+				}
+				if reason.Message == "" {
+					t.Errorf("expected a message for the rule %s", reason.Rule)
+				}
+			}
+		})
+	}
+}
+
+func TestConventionalTitleLabels(t *testing.T) {
+	cfg := config.Default()
+
+	testCases := map[string][]string{
+		"feat(api)!: remove the v1 endpoints": {"kind/feature", "kind/breaking-change"},
+		"fix: crash on startup":               {"kind/bug"},
+		"Fix: crash on startup":               {"kind/bug"},
+		"unknown: crash on startup":           nil,
+		"Fix the crash on startup":            nil,
+		"fix:crash on startup":                nil,
+	}
+
+	for title, expected := range testCases {
+		labels := conventionalTitleLabels(cfg, title)
+		if len(labels) != len(expected) {
+			t.Fatalf("expected %v for %q, got %v", expected, title, labels)
+		}
+		for i := range labels {
+			if labels[i] != expected[i] {
+				t.Errorf("expected %v for %q, got %v", expected, title, labels)
+			}
+		}
+	}
+}
+
+func TestBlockerCheckRun(t *testing.T) {
+	c, fake, recorder := newTestContext("blocker")
+	fake.Checks = true
+
+	payload := testPullRequestPayload(model.PullRequestActionLabeled, "alice", "do-not-merge")
+	if err := processTestEvent(t, c, fake, model.EventTypePullRequest, payload); err != nil {
+		t.Fatal(err)
+	}
+	checkRun, _ := fake.GetCheckRun("org", "repo", "abc", blockerName)
+	if checkRun.GetStatus() != "completed" || checkRun.GetConclusion() != "failure" {
+		t.Fatalf("expected a failed check run, got %s %s", checkRun.GetStatus(), checkRun.GetConclusion())
+	}
+
+	payload = testPullRequestPayload(model.PullRequestActionUnlabeled, "alice")
+	if err := processTestEvent(t, c, fake, model.EventTypePullRequest, payload); err != nil {
+		t.Fatal(err)
+	}
+	checkRun, _ = fake.GetCheckRun("org", "repo", "abc", blockerName)
+	if checkRun.GetConclusion() != "success" {
+		t.Fatalf("expected a successful check run, got %s", checkRun.GetConclusion())
+	}
+
+	expected := []string{"CreateCheckRun", "UpdateCheckRun"}
+	if calls := actionNames(recorder.Actions("org", "repo")); !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected the changes %v, got %v", expected, calls)
+	}
+}
+
+func TestBuildBlockerStatusTruncation(t *testing.T) {
+	label := "do-not-merge/" + strings.Repeat("é", 150)
+	reasons := []blockReason{{Rule: "do-not-merge/*", Label: label}, {Rule: "hold", Label: "do-not-merge/hold"}}
+
+	state, desc := buildBlockerStatus(reasons)
+	if state != "pending" {
+		t.Fatalf("expected a pending status, got %s", state)
+	}
+	if !utf8.ValidString(desc) || utf8.RuneCountInString(desc) != maxStatusDescription || !strings.HasSuffix(desc, "é...") {
+		t.Fatalf("expected the description to be truncated to %d characters, got %q", maxStatusDescription, desc)
+	}
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mattermost/chewbacca/internal/git"
+	"github.com/mattermost/chewbacca/internal/utils"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var cherryPickRe = regexp.MustCompile(`(?mi)^/cherry-pick\s+(\S+)\s*$`)
+
+// cherryPickSkippedLabels are the labels which are not carried over to the cherry-pick PRs.
+var cherryPickSkippedLabels = sets.New[string](lgtmLabel, approvedLabel, firstTimeContributorLabel)
+
+// cherryPickPlugin handles the /cherry-pick command, which cherry-picks a merged PR on
+// another branch and opens a new PR. The command is queued on the open PRs until they
+// are merged.
+type cherryPickPlugin struct{}
+
+func (p *cherryPickPlugin) Name() string {
+	return "cherry-pick"
+}
+
+func (p *cherryPickPlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypePullRequest:  {model.PullRequestActionClosed},
+		model.EventTypeIssueComment: {model.IssueCommentActionCreated},
+	}
+}
+
+func (p *cherryPickPlugin) Handle(c *Context, e *Event) error {
+	if e.PullRequest != nil {
+		return handleCherryPickMerged(c, e)
+	}
+	if !e.IsPullRequest {
+		return nil
+	}
+
+	comment := e.IssueComment.GetComment()
+	matches := cherryPickRe.FindAllStringSubmatch(comment.GetBody(), -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	requester := comment.GetUser().GetLogin()
+	isMember, err := c.GitHub.IsMember(e.Org, requester)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the membership")
+	}
+	if !isMember {
+		c.Logger.Info("not member")
+		return c.GitHub.CreateComment(e.Org, e.Repo, e.Number, utils.FormatICResponse(comment, "only the org members can request cherry-picks."))
+	}
+
+	pr, err := c.GitHub.GetPullRequest(e.Org, e.Repo, e.Number)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the PR#%d", e.Number)
+	}
+
+	if !pr.GetMerged() {
+		if pr.GetState() == "closed" {
+			return c.GitHub.CreateComment(e.Org, e.Repo, e.Number, utils.FormatICResponse(comment, "the PR was closed without being merged, it can't be cherry-picked."))
+		}
+		var branches []string
+		for _, match := range matches {
+			branches = append(branches, "`"+match[1]+"`")
+		}
+		return c.GitHub.CreateComment(e.Org, e.Repo, e.Number, utils.FormatICResponse(comment, fmt.Sprintf("the cherry-pick on %s will be done once the PR is merged.", strings.Join(branches, ", "))))
+	}
+
+	requested := make(map[string]string)
+	var branches []string
+	for _, match := range matches {
+		requested[match[1]] = requester
+		branches = append(branches, match[1])
+	}
+
+	return cherryPickBranches(c, e.Org, e.Repo, pr, branches, requested)
+}
+
+// handleCherryPickMerged runs the cherry-picks queued on the PR once it's merged.
+func handleCherryPickMerged(c *Context, e *Event) error {
+	pr := e.PullRequest.GetPullRequest()
+	if !pr.GetMerged() {
+		return nil
+	}
+
+	comments, err := c.GitHub.ListIssueComments(e.Org, e.Repo, e.Number)
+	if err != nil {
+		return errors.Wrap(err, "failed to list the comments")
+	}
+
+	requested := make(map[string]string)
+	var branches []string
+	for _, comment := range comments {
+		for _, match := range cherryPickRe.FindAllStringSubmatch(comment.GetBody(), -1) {
+			if _, ok := requested[match[1]]; ok {
+				continue
+			}
+			requester := comment.GetUser().GetLogin()
+			isMember, err := c.GitHub.IsMember(e.Org, requester)
+			if err != nil {
+				return errors.Wrap(err, "failed to get the membership")
+			}
+			if !isMember {
+				continue
+			}
+			requested[match[1]] = requester
+			branches = append(branches, match[1])
+		}
+	}
+
+	return cherryPickBranches(c, e.Org, e.Repo, pr, branches, requested)
+}
+
+// cherryPickBranches cherry-picks the PR on each branch, requested by the given users.
+// A failed cherry-pick is reported to its requester instead of failing the plugin, so
+// the other branches are not cherry-picked again by a retry.
+func cherryPickBranches(c *Context, org, repo string, pr *github.PullRequest, branches []string, requested map[string]string) error {
+	for _, branch := range branches {
+		err := cherryPick(c, org, repo, pr, branch, requested[branch])
+		if err == nil {
+			continue
+		}
+
+		c.Logger.WithError(err).WithField("branch", branch).Error("failed to cherry-pick")
+		message := fmt.Sprintf("the cherry-pick on `%s` failed. Comment `/cherry-pick %s` to try again, or do it manually.", branch, branch)
+		if err := c.GitHub.CreateComment(org, repo, pr.GetNumber(), utils.FormatSimpleResponse(requested[branch], message)); err != nil {
+			return errors.Wrap(err, "failed to report the cherry-pick failure")
+		}
+	}
+
+	return nil
+}
+
+// cherryPick cherry-picks the merge commit of the PR on the branch, pushes it to the
+// fork and opens a new PR carrying over the labels and the release note of the PR.
+// The requester is notified of the result, including the conflicts. Nothing is done
+// when the cherry-pick PR is already open, so the cherry-pick can be retried.
+func cherryPick(c *Context, org, repo string, pr *github.PullRequest, branch, requester string) error {
+	number := pr.GetNumber()
+	logger := c.Logger.WithField("branch", branch)
+	reply := func(message string) error {
+		return c.GitHub.CreateComment(org, repo, number, utils.FormatSimpleResponse(requester, message))
+	}
+
+	if c.Git == nil {
+		logger.Info("cherry-picks are disabled, skipping")
+		return nil
+	}
+
+	headOwner := org
+	if c.CherryPickFork != "" {
+		headOwner = c.CherryPickFork
+	}
+	head := fmt.Sprintf("cherry-pick-%d-to-%s", number, branch)
+
+	existing, err := findPullRequestFrom(c, org, repo, branch, headOwner+":"+head)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		logger.WithField("cherry_pick_pr", existing.GetNumber()).Info("cherry-pick PR already open")
+		return reply(fmt.Sprintf("the cherry-pick on `%s` is already in #%d.", branch, existing.GetNumber()))
+	}
+
+	if headOwner != org {
+		if err := c.GitHub.EnsureFork(org, repo, headOwner); err != nil {
+			return errors.Wrapf(err, "failed to fork %s/%s", org, repo)
+		}
+	}
+
+	workspace, err := c.Git.Clone(org, repo)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := workspace.Clean(); err != nil {
+			logger.WithError(err).Warn("failed to clean the workspace")
+		}
+	}()
+
+	if err := workspace.CheckoutNewBranch(head, branch); err != nil {
+		logger.WithError(err).Info("failed to checkout the branch")
+		return reply(fmt.Sprintf("the cherry-pick on `%s` failed, the branch could not be checked out.", branch))
+	}
+
+	if err := workspace.CherryPick(pr.GetMergeCommitSHA()); err != nil {
+		if conflict, ok := err.(*git.ConflictError); ok {
+			logger.WithField("files", conflict.Files).Info("cherry-pick conflicts")
+			return reply(fmt.Sprintf("the cherry-pick on `%s` has conflicts in these files, it needs to be done manually:\n\n- `%s`", branch, strings.Join(conflict.Files, "`\n- `")))
+		}
+		return err
+	}
+
+	if err := workspace.Push(headOwner, repo, head); err != nil {
+		return err
+	}
+
+	if headOwner != org {
+		head = headOwner + ":" + head
+	}
+	title := fmt.Sprintf("[%s] %s", branch, pr.GetTitle())
+	body := fmt.Sprintf("Automated cherry-pick of #%d on `%s`, requested by @%s.\n\n#### Release Note\n\n```release-note\n%s\n```\n", number, branch, requester, getReleaseNote(pr.GetBody()))
+	cherryPickPR, err := c.GitHub.CreatePullRequest(org, repo, title, head, branch, body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the cherry-pick PR on %s", branch)
+	}
+
+	var labels []string
+	for _, label := range pr.Labels {
+		if !cherryPickSkippedLabels.Has(label.GetName()) {
+			labels = append(labels, label.GetName())
+		}
+	}
+	if len(labels) > 0 {
+		if err := c.GitHub.AddLabels(org, repo, cherryPickPR.GetNumber(), labels); err != nil {
+			logger.WithError(err).Error("failed to carry over the labels")
+		}
+	}
+
+	logger.WithField("cherry_pick_pr", cherryPickPR.GetNumber()).Info("cherry-pick PR created")
+	return reply(fmt.Sprintf("the cherry-pick on `%s` is in #%d.", branch, cherryPickPR.GetNumber()))
+}
+
+// findPullRequestFrom returns the open PR from the head, given as owner:branch, to the
+// base branch, or nil if there is none.
+func findPullRequestFrom(c *Context, org, repo, base, head string) (*github.PullRequest, error) {
+	prs, err := c.GitHub.ListPullRequests(org, repo, base)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the PRs targeting %s", base)
+	}
+	for _, pr := range prs {
+		if strings.EqualFold(pr.GetHead().GetLabel(), head) {
+			return pr, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/chewbacca/internal/git"
+	"github.com/mattermost/chewbacca/model"
+)
+
+func TestCherryPickRetry(t *testing.T) {
+	c, fake, recorder := newTestContext("cherry-pick", "bob")
+	c.Git = git.NewClient(func(org, repo string) (string, error) {
+		return "", errors.New("no credentials")
+	}, "chewbacca", "chewbacca@example.com", c.Logger)
+
+	fake.Observe(model.EventTypePullRequest, []byte(`{
+		"action": "closed",
+		"number": 7,
+		"pull_request": {"number": 7, "state": "closed", "merged": true, "merge_commit_sha": "abc", "user": {"login": "alice"}, "base": {"ref": "master"}},
+		"repository": {"name": "repo", "owner": {"login": "org"}}
+	}`))
+	fake.Observe(model.EventTypePullRequest, []byte(`{
+		"action": "opened",
+		"number": 8,
+		"pull_request": {"number": 8, "state": "open", "user": {"login": "chewbacca"}, "head": {"label": "org:cherry-pick-7-to-release-9.1"}, "base": {"ref": "release-9.1"}},
+		"repository": {"name": "repo", "owner": {"login": "org"}}
+	}`))
+
+	payload := testCommentPayload("bob", "alice", "/cherry-pick release-9.1\n/cherry-pick release-9.2")
+	if err := processTestEvent(t, c, fake, model.EventTypeIssueComment, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	actions := recorder.Actions("org", "repo")
+	if len(actions) != 2 || actions[0].Action != "CreateComment" || actions[1].Action != "CreateComment" {
+		t.Fatalf("expected two comments, got %+v", actions)
+	}
+	if !strings.Contains(actions[0].Details, "is already in #8") {
+		t.Errorf("expected the existing cherry-pick PR to be linked, got %q", actions[0].Details)
+	}
+	if !strings.Contains(actions[1].Details, "the cherry-pick on `release-9.2` failed") {
+		t.Errorf("expected the failure to be reported, got %q", actions[1].Details)
+	}
+}
+
+func TestCherryPickQueuedAfterManyComments(t *testing.T) {
+	c, fake, recorder := newTestContext("cherry-pick", "bob")
+	c.Git = git.NewClient(func(org, repo string) (string, error) {
+		return "", errors.New("no credentials")
+	}, "chewbacca", "chewbacca@example.com", c.Logger)
+
+	for i := 0; i < 40; i++ {
+		fake.Observe(model.EventTypeIssueComment, testCommentPayload("alice", "alice", "Fixed the review comments."))
+	}
+	fake.Observe(model.EventTypeIssueComment, testCommentPayload("bob", "alice", "/cherry-pick release-9.1"))
+
+	payload := []byte(`{
+		"action": "closed",
+		"number": 7,
+		"pull_request": {"number": 7, "state": "closed", "merged": true, "merge_commit_sha": "abc", "user": {"login": "alice"}, "base": {"ref": "master"}},
+		"repository": {"name": "repo", "owner": {"login": "org"}}
+	}`)
+	if err := processTestEvent(t, c, fake, model.EventTypePullRequest, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	actions := recorder.Actions("org", "repo")
+	if len(actions) != 1 || !strings.Contains(actions[0].Details, "the cherry-pick on `release-9.1` failed") {
+		t.Fatalf("expected the queued cherry-pick to be run, got %+v", actions)
+	}
+}
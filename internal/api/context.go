@@ -1,15 +1,16 @@
 package api
 
 import (
+	"net/http"
+
+	"github.com/mattermost/chewbacca/internal/config"
+	"github.com/mattermost/chewbacca/internal/git"
+	"github.com/mattermost/chewbacca/internal/queue"
+
 	"github.com/google/go-github/v31/github"
 	"github.com/sirupsen/logrus"
 )
 
-// Actions describes the interface for actions.
-type Actions interface {
-	HandleReleaseNotesPR(c *Context, pr *github.PullRequestEvent)
-}
-
 // GitHub describes the interface required to persist changes made via API requests.
 type GitHub interface {
 	ValidateSignature(receivedHash []string, bodyBuffer []byte) error
@@ -22,16 +23,76 @@ type GitHub interface {
 	GetComments(org, repo string, number int) ([]*github.IssueComment, error)
 	IsMember(org, repo string) (bool, error)
 	SetStatus(org, repo, sha, state, message string) error
+	SupportsChecks() bool
+	CreateCheckRun(org, repo string, checkRun github.CreateCheckRunOptions) error
+	GetCheckRun(org, repo, sha, name string) (*github.CheckRun, error)
+	UpdateCheckRun(org, repo string, id int64, checkRun github.UpdateCheckRunOptions) error
 	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
+	ListOpenIssues(org, repo string) ([]*github.Issue, error)
+	CloseIssue(org, repo string, number int) error
+	CountMergedPullRequests(org, repo, author string) (int, error)
+	CreatePullRequest(org, repo, title, head, base, body string) (*github.PullRequest, error)
+	EnsureFork(org, repo, owner string) error
+	Token(org string) (string, error)
+	CompareCommits(org, repo, base, head string) ([]*github.RepositoryCommit, error)
+	ListPullRequestsWithCommit(org, repo, sha string) ([]*github.PullRequest, error)
+	ListPullRequests(org, repo, base string) ([]*github.PullRequest, error)
+	ListPullRequestFiles(org, repo string, number int) ([]*github.CommitFile, error)
+	RequestReviewers(org, repo string, number int, reviewers []string) error
 	ListRepoLabels(org, repo string) ([]*github.Label, error)
+	EditLabel(org, repo, name string, label github.Label) error
+	DeleteLabel(org, repo, name string) error
+	ListOrgRepos(org string) ([]*github.Repository, error)
+	GetFileContent(org, repo, path, ref string) ([]byte, error)
+	SetInstallation(org string, installationID int64)
+	RemoveInstallation(org string)
+	GetRateLimit() (*github.Rate, error)
+}
+
+// EventQueue describes the interface required to process the webhook events asynchronously.
+type EventQueue interface {
+	Enqueue(eventType, deliveryID string, payload []byte) error
+	Depth() (int, error)
+	DeadLetters() ([]*queue.Job, error)
+	RetryDeadLetter(id uint64) error
+}
+
+// DeliveryStore describes the interface required to detect the redelivered webhooks.
+type DeliveryStore interface {
+	Seen(deliveryID string) bool
+	Forget(deliveryID string)
+}
+
+// WebhookArchive describes the interface required to archive the raw webhooks.
+type WebhookArchive interface {
+	Write(headers http.Header, body []byte) error
 }
 
 // Context provides the API with all necessary data and interfaces for responding to requests.
 //
 // It is cloned before each request, allowing per-request changes such as logger annotations.
 type Context struct {
-	GitHub    GitHub
-	Actions   Actions
+	GitHub     GitHub
+	Plugins    *Registry
+	Config     *config.Store
+	Queue      EventQueue
+	Deliveries DeliveryStore
+	// Archive stores the raw webhooks for replaying them. Nothing is archived when nil.
+	Archive WebhookArchive
+	// MaxQueueDepth is the number of queued events above which the server is not ready.
+	MaxQueueDepth int
+	// AdminToken protects the admin endpoints, which are disabled when empty.
+	AdminToken string
+	// Git clones the repositories for the cherry-picks, which are disabled when nil, like
+	// in dry-run mode.
+	Git *git.Client
+	// CherryPickFork is the owner of the forks the cherry-pick branches are pushed to.
+	// The branches are pushed to the repositories themselves when empty.
+	CherryPickFork string
+	// DryRun records the changes of the plugins in the Recorder instead of making
+	// them, for all the repositories. It can also be enabled per repository.
+	DryRun    bool
+	Recorder  *Recorder
 	RequestID string
 	Logger    logrus.FieldLogger
 }
@@ -39,8 +100,34 @@ type Context struct {
 // Clone creates a shallow copy of context, allowing clones to apply per-request changes.
 func (c *Context) Clone() *Context {
 	return &Context{
-		GitHub:  c.GitHub,
-		Actions: c.Actions,
-		Logger:  c.Logger,
+		GitHub:         c.GitHub,
+		Plugins:        c.Plugins,
+		Config:         c.Config,
+		Queue:          c.Queue,
+		Deliveries:     c.Deliveries,
+		Archive:        c.Archive,
+		MaxQueueDepth:  c.MaxQueueDepth,
+		AdminToken:     c.AdminToken,
+		Git:            c.Git,
+		CherryPickFork: c.CherryPickFork,
+		DryRun:         c.DryRun,
+		Recorder:       c.Recorder,
+		Logger:         c.Logger,
+	}
+}
+
+// RepoConfig returns the configuration of the given repository, falling back to the
+// defaults when it cannot be loaded.
+func (c *Context) RepoConfig(org, repo string) *config.RepoConfig {
+	if c.Config == nil {
+		return config.Default()
+	}
+
+	cfg, err := c.Config.Get(org, repo)
+	if err != nil {
+		c.Logger.WithError(err).Warnf("failed to load the configuration of %s/%s, using the defaults", org, repo)
+		return config.Default()
 	}
+
+	return cfg
 }
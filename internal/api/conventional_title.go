@@ -0,0 +1,127 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mattermost/chewbacca/internal/config"
+	"github.com/mattermost/chewbacca/internal/utils"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var conventionalTitleRe = regexp.MustCompile(`^(\w+)(?:\(([^()]+)\))?(!)?: +(\S.*)$`)
+
+// conventionalTitle is a PR title following the conventional commits format, like
+// `feat(api)!: remove the v1 endpoints`.
+type conventionalTitle struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+}
+
+// parseConventionalTitle parses the PR title, returning nil if it doesn't follow the
+// conventional commits format.
+func parseConventionalTitle(title string) *conventionalTitle {
+	match := conventionalTitleRe.FindStringSubmatch(strings.TrimSpace(title))
+	if match == nil {
+		return nil
+	}
+	return &conventionalTitle{
+		Type:        strings.ToLower(match[1]),
+		Scope:       match[2],
+		Breaking:    match[3] != "",
+		Description: match[4],
+	}
+}
+
+// conventionalTitleLabels returns the labels derived from the title, or nil if the
+// title doesn't follow the conventional commits format with an allowed type.
+func conventionalTitleLabels(cfg *config.RepoConfig, title string) []string {
+	parsed := parseConventionalTitle(title)
+	if parsed == nil {
+		return nil
+	}
+	kindLabel, ok := cfg.TitleTypeLabel(parsed.Type)
+	if !ok {
+		return nil
+	}
+
+	var labels []string
+	if kindLabel != "" {
+		labels = append(labels, kindLabel)
+	}
+	if parsed.Breaking && cfg.BreakingChangeLabel != "" {
+		labels = append(labels, cfg.BreakingChangeLabel)
+	}
+	return labels
+}
+
+// checkConventionalTitle returns a message explaining the expected format when the
+// title doesn't follow the conventional commits format with an allowed type.
+func checkConventionalTitle(cfg *config.RepoConfig, title string) string {
+	var types []string
+	for _, t := range cfg.TitleTypes {
+		types = append(types, "`"+t.Type+"`")
+	}
+	format := fmt.Sprintf("The title should follow the format `type(scope)!: description`, where the type is one of %s. The scope is optional, and `!` marks a breaking change, like in `feat(api)!: remove the v1 endpoints`.", strings.Join(types, ", "))
+
+	parsed := parseConventionalTitle(title)
+	if parsed == nil {
+		return format
+	}
+	if _, ok := cfg.TitleTypeLabel(parsed.Type); !ok {
+		return fmt.Sprintf("`%s` is not a known type. %s", parsed.Type, format)
+	}
+	return ""
+}
+
+// conventionalTitlePlugin applies the kind and breaking change labels derived from
+// the conventional commits format of the PR titles.
+type conventionalTitlePlugin struct{}
+
+func (p *conventionalTitlePlugin) Name() string {
+	return "conventional-title"
+}
+
+func (p *conventionalTitlePlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypePullRequest: {
+			model.PullRequestActionOpened,
+			model.PullRequestActionReopened,
+			model.PullRequestActionEdited,
+		},
+	}
+}
+
+func (p *conventionalTitlePlugin) Handle(c *Context, e *Event) error {
+	cfg := c.RepoConfig(e.Org, e.Repo)
+	pr := e.PullRequest.GetPullRequest()
+
+	wanted := sets.New[string](conventionalTitleLabels(cfg, pr.GetTitle())...)
+	currentLabels := utils.LabelsSet(pr.Labels)
+
+	// Remove the labels derived from the previous title which no longer apply.
+	if changes := e.PullRequest.GetChanges(); changes != nil && changes.Title != nil && changes.Title.From != nil {
+		previous := sets.New[string](conventionalTitleLabels(cfg, *changes.Title.From)...)
+		for _, label := range sets.List(previous.Difference(wanted).Intersection(currentLabels)) {
+			c.Logger.Infof("removing the %s label derived from the previous title", label)
+			if err := c.GitHub.RemoveLabel(e.Org, e.Repo, e.Number, label); err != nil {
+				return errors.Wrapf(err, "failed to remove the %s label", label)
+			}
+		}
+	}
+
+	if missing := wanted.Difference(currentLabels); missing.Len() > 0 {
+		c.Logger.Infof("adding the labels %v derived from the title", sets.List(missing))
+		if err := c.GitHub.AddLabels(e.Org, e.Repo, e.Number, sets.List(missing)); err != nil {
+			return errors.Wrap(err, "failed to add the labels derived from the title")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v31/github"
+	"github.com/sirupsen/logrus"
+)
+
+// RecordedAction is a change the bot would have made on GitHub in dry-run mode.
+type RecordedAction struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id"`
+	Org       string    `json:"org"`
+	Repo      string    `json:"repo"`
+	Number    int       `json:"number,omitempty"`
+	Action    string    `json:"action"`
+	Details   string    `json:"details,omitempty"`
+}
+
+// String describes the action in a report line.
+func (a *RecordedAction) String() string {
+	target := fmt.Sprintf("%s/%s", a.Org, a.Repo)
+	if a.Number != 0 {
+		target += fmt.Sprintf("#%d", a.Number)
+	}
+	if a.Details == "" {
+		return fmt.Sprintf("%s %s", target, a.Action)
+	}
+	return fmt.Sprintf("%s %s: %s", target, a.Action, a.Details)
+}
+
+// Recorder keeps the latest actions recorded in dry-run mode.
+type Recorder struct {
+	size int
+
+	mu      sync.Mutex
+	actions []*RecordedAction
+}
+
+// NewRecorder creates a recorder keeping the given number of actions.
+func NewRecorder(size int) *Recorder {
+	return &Recorder{size: size}
+}
+
+// Record adds the action, dropping the oldest one when the recorder is full.
+func (r *Recorder) Record(action *RecordedAction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.actions = append(r.actions, action)
+	if len(r.actions) > r.size {
+		r.actions = r.actions[len(r.actions)-r.size:]
+	}
+}
+
+// Actions returns the recorded actions of the repository, or of all the repositories
+// when org and repo are empty, from the oldest to the latest.
+func (r *Recorder) Actions(org, repo string) []*RecordedAction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	actions := []*RecordedAction{}
+	for _, action := range r.actions {
+		if (org == "" || strings.EqualFold(action.Org, org)) && (repo == "" || strings.EqualFold(action.Repo, repo)) {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// dryRunGitHub records the changes instead of making them on GitHub. The reads are
+// still done on GitHub.
+type dryRunGitHub struct {
+	GitHub
+
+	recorder  *Recorder
+	requestID string
+	logger    logrus.FieldLogger
+}
+
+// enableDryRun replaces the GitHub client of the context with a client recording the
+// changes. The cherry-picks are disabled since they push to the repositories.
+func (c *Context) enableDryRun() {
+	if _, ok := c.GitHub.(*dryRunGitHub); ok {
+		return
+	}
+
+	c.GitHub = &dryRunGitHub{
+		GitHub:    c.GitHub,
+		recorder:  c.Recorder,
+		requestID: c.RequestID,
+		logger:    c.Logger,
+	}
+	c.Git = nil
+	c.Logger = c.Logger.WithField("dry_run", true)
+}
+
+// enableRepoDryRun enables the dry-run mode when it's enabled for all the repositories
+// or in the configuration of the repository.
+func (c *Context) enableRepoDryRun(org, repo string) {
+	if c.DryRun || c.RepoConfig(org, repo).DryRun {
+		c.enableDryRun()
+	}
+}
+
+func (g *dryRunGitHub) record(org, repo string, number int, action, details string) error {
+	g.logger.WithFields(logrus.Fields{
+		"org":     org,
+		"repo":    repo,
+		"number":  number,
+		"action":  action,
+		"details": details,
+	}).Info("change skipped in dry-run mode")
+
+	if g.recorder != nil {
+		g.recorder.Record(&RecordedAction{
+			Time:      time.Now(),
+			RequestID: g.requestID,
+			Org:       org,
+			Repo:      repo,
+			Number:    number,
+			Action:    action,
+			Details:   details,
+		})
+	}
+
+	return nil
+}
+
+func (g *dryRunGitHub) CreateComment(org, repo string, number int, comment string) error {
+	return g.record(org, repo, number, "CreateComment", comment)
+}
+
+func (g *dryRunGitHub) CreateLabel(org, repo string, label github.Label) error {
+	return g.record(org, repo, 0, "CreateLabel", fmt.Sprintf("%s (color %s): %s", label.GetName(), label.GetColor(), label.GetDescription()))
+}
+
+func (g *dryRunGitHub) EditLabel(org, repo, name string, label github.Label) error {
+	return g.record(org, repo, 0, "EditLabel", fmt.Sprintf("%s to %s (color %s): %s", name, label.GetName(), label.GetColor(), label.GetDescription()))
+}
+
+func (g *dryRunGitHub) DeleteLabel(org, repo, name string) error {
+	return g.record(org, repo, 0, "DeleteLabel", name)
+}
+
+func (g *dryRunGitHub) AddLabels(org, repo string, number int, labels []string) error {
+	return g.record(org, repo, number, "AddLabels", strings.Join(labels, ", "))
+}
+
+func (g *dryRunGitHub) RemoveLabel(org, repo string, number int, label string) error {
+	return g.record(org, repo, number, "RemoveLabel", label)
+}
+
+func (g *dryRunGitHub) SetStatus(org, repo, sha, state, message string) error {
+	return g.record(org, repo, 0, "SetStatus", fmt.Sprintf("%s on %s: %s", state, sha, message))
+}
+
+func (g *dryRunGitHub) CreateCheckRun(org, repo string, checkRun github.CreateCheckRunOptions) error {
+	return g.record(org, repo, 0, "CreateCheckRun", fmt.Sprintf("%s %s on %s: %s", checkRun.Name, checkRun.GetConclusion(), checkRun.HeadSHA, checkRun.GetOutput().GetTitle()))
+}
+
+func (g *dryRunGitHub) UpdateCheckRun(org, repo string, id int64, checkRun github.UpdateCheckRunOptions) error {
+	return g.record(org, repo, 0, "UpdateCheckRun", fmt.Sprintf("%s %s: %s", checkRun.Name, checkRun.GetConclusion(), checkRun.GetOutput().GetTitle()))
+}
+
+func (g *dryRunGitHub) CloseIssue(org, repo string, number int) error {
+	return g.record(org, repo, number, "CloseIssue", "")
+}
+
+func (g *dryRunGitHub) RequestReviewers(org, repo string, number int, reviewers []string) error {
+	return g.record(org, repo, number, "RequestReviewers", strings.Join(reviewers, ", "))
+}
+
+func (g *dryRunGitHub) CreatePullRequest(org, repo, title, head, base, body string) (*github.PullRequest, error) {
+	g.record(org, repo, 0, "CreatePullRequest", fmt.Sprintf("%s from %s to %s", title, head, base))
+	return &github.PullRequest{Title: github.String(title)}, nil
+}
+
+func (g *dryRunGitHub) EnsureFork(org, repo, owner string) error {
+	return g.record(org, repo, 0, "EnsureFork", owner)
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/config"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestDryRun(t *testing.T) {
+	recorder := NewRecorder(2)
+	c := &Context{Recorder: recorder, RequestID: "request", Logger: logrus.New()}
+	c.enableDryRun()
+
+	// The changes must not reach the wrapped client, which is nil here.
+	if err := c.GitHub.AddLabels("org", "repo", 1, []string{"kind/bug", "size/XS"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.GitHub.CreateComment("org", "other", 2, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.GitHub.RemoveLabel("org", "repo", 1, "size/XS"); err != nil {
+		t.Fatal(err)
+	}
+
+	actions := recorder.Actions("", "")
+	if len(actions) != 2 || actions[0].Action != "CreateComment" || actions[1].Action != "RemoveLabel" {
+		t.Fatalf("expected the two latest actions, got %+v", actions)
+	}
+	if actions := recorder.Actions("org", "repo"); len(actions) != 1 || actions[0].Details != "size/XS" || actions[0].RequestID != "request" {
+		t.Fatalf("expected the action of the repository, got %+v", actions)
+	}
+}
+
+func TestPeriodicJobsDryRun(t *testing.T) {
+	logger := logrus.New()
+	// The fake GitHub doesn't record its changes, the recorder only gets the changes
+	// skipped in dry-run mode.
+	fake := NewFakeGitHub(nil)
+	recorder := NewRecorder(100)
+	c := &Context{
+		GitHub:   fake,
+		Config:   config.NewStore(fake, time.Minute, logger),
+		DryRun:   true,
+		Recorder: recorder,
+		Logger:   logger,
+	}
+	fake.Observe(model.EventTypePullRequest, []byte(`{
+		"action": "opened",
+		"number": 7,
+		"pull_request": {"number": 7, "state": "open", "updated_at": "2020-01-01T00:00:00Z", "user": {"login": "alice"}, "labels": [{"name": "lifecycle/rotten"}]},
+		"repository": {"name": "repo", "owner": {"login": "org"}}
+	}`))
+
+	lifecycleJob, err := NewLifecycleJob(c, []string{"org/repo"}, time.Hour, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lifecycleJob.run()
+
+	manifest := &config.LabelManifest{Labels: []config.Label{{Name: "kind/bug", Color: "e11d21"}}}
+	NewLabelSyncJob(c, []string{"org"}, manifest, time.Hour, LabelSyncOptions{Delete: true}).run()
+
+	issues, _ := fake.ListOpenIssues("org", "repo")
+	if len(issues) != 1 {
+		t.Fatal("expected the rotten issue not to be closed")
+	}
+	labels, _ := fake.ListRepoLabels("org", "repo")
+	if len(labels) != 1 || labels[0].GetName() != lifecycleRottenLabel {
+		t.Fatalf("expected the labels of the repository to be unchanged, got %v", labels)
+	}
+
+	calls := make(map[string]int)
+	for _, action := range recorder.Actions("org", "repo") {
+		calls[action.Action]++
+	}
+	if calls["CloseIssue"] != 1 || calls["CreateLabel"] != 1 || calls["DeleteLabel"] != 1 {
+		t.Fatalf("expected the changes to be recorded, got %v", calls)
+	}
+}
@@ -0,0 +1,465 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// FakeGitHub is an in-memory GitHub used to replay webhooks offline. Its state is
+// seeded from the replayed webhooks with Observe and updated by the changes of the
+// plugins, which are also recorded.
+type FakeGitHub struct {
+	// Members are the logins considered members of all the organizations.
+	Members sets.Set[string]
+	// Files are the contents of the files, by path, of all the repositories, like the
+	// .chewbacca.yaml configuration file.
+	Files map[string][]byte
+	// Checks enables the check runs. The merge blocker is otherwise set as a status.
+	Checks bool
+
+	recorder *Recorder
+
+	mu         sync.Mutex
+	issues     map[string]*github.Issue
+	prs        map[string]*github.PullRequest
+	comments   map[string][]*github.IssueComment
+	repoLabels map[string]map[string]*github.Label
+	checkRuns  map[string]*github.CheckRun
+	nextID     int64
+}
+
+// NewFakeGitHub creates an empty fake GitHub recording the changes in the recorder.
+func NewFakeGitHub(recorder *Recorder) *FakeGitHub {
+	return &FakeGitHub{
+		Members:    sets.New[string](),
+		Files:      make(map[string][]byte),
+		recorder:   recorder,
+		issues:     make(map[string]*github.Issue),
+		prs:        make(map[string]*github.PullRequest),
+		comments:   make(map[string][]*github.IssueComment),
+		repoLabels: make(map[string]map[string]*github.Label),
+		checkRuns:  make(map[string]*github.CheckRun),
+	}
+}
+
+func issueKey(org, repo string, number int) string {
+	return fmt.Sprintf("%s/%s#%d", strings.ToLower(org), strings.ToLower(repo), number)
+}
+
+func repoKey(org, repo string) string {
+	return strings.ToLower(org) + "/" + strings.ToLower(repo)
+}
+
+// Observe updates the state with the issue, PR and comment of the webhook, as GitHub
+// has them when the webhook is sent.
+func (g *FakeGitHub) Observe(eventType string, payload []byte) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch eventType {
+	case model.EventTypePullRequest:
+		event := model.PullRequestEventFromJSON(bytes.NewReader(payload))
+		if event == nil {
+			return
+		}
+		org, repo := event.GetRepo().GetOwner().GetLogin(), event.GetRepo().GetName()
+		pr := event.GetPullRequest()
+		g.prs[issueKey(org, repo, pr.GetNumber())] = pr
+		g.issues[issueKey(org, repo, pr.GetNumber())] = &github.Issue{
+			Number:           pr.Number,
+			Title:            pr.Title,
+			Body:             pr.Body,
+			State:            pr.State,
+			User:             pr.User,
+			Labels:           pr.Labels,
+			UpdatedAt:        pr.UpdatedAt,
+			PullRequestLinks: &github.PullRequestLinks{URL: pr.URL},
+		}
+		g.observeLabels(org, repo, pr.Labels)
+	case model.EventTypeIssueComment:
+		event := model.IssueCommentEventFromJSON(bytes.NewReader(payload))
+		if event == nil {
+			return
+		}
+		org, repo := event.GetRepo().GetOwner().GetLogin(), event.GetRepo().GetName()
+		issue := event.GetIssue()
+		key := issueKey(org, repo, issue.GetNumber())
+		g.issues[key] = issue
+		if pr, ok := g.prs[key]; ok {
+			pr.Labels = issue.Labels
+		} else if issue.IsPullRequest() {
+			g.prs[key] = &github.PullRequest{
+				Number: issue.Number,
+				Title:  issue.Title,
+				Body:   issue.Body,
+				State:  issue.State,
+				User:   issue.User,
+				Labels: issue.Labels,
+			}
+		}
+		g.observeLabels(org, repo, issue.Labels)
+		if event.GetAction() == model.IssueCommentActionCreated {
+			g.comments[key] = append(g.comments[key], event.GetComment())
+		}
+	}
+}
+
+func (g *FakeGitHub) observeLabels(org, repo string, labels []*github.Label) {
+	for _, label := range labels {
+		g.repoLabel(org, repo, label.GetName(), label)
+	}
+}
+
+// repoLabel returns the label of the repository, creating it if needed like GitHub
+// does when adding a missing label to an issue.
+func (g *FakeGitHub) repoLabel(org, repo, name string, label *github.Label) *github.Label {
+	labels, ok := g.repoLabels[repoKey(org, repo)]
+	if !ok {
+		labels = make(map[string]*github.Label)
+		g.repoLabels[repoKey(org, repo)] = labels
+	}
+	if existing, ok := labels[strings.ToLower(name)]; ok {
+		return existing
+	}
+	if label == nil {
+		label = &github.Label{Name: github.String(name), Color: github.String("ededed")}
+	}
+	labels[strings.ToLower(name)] = label
+	return label
+}
+
+// setIssueLabels updates the labels of the issue and of its PR.
+func (g *FakeGitHub) setIssueLabels(org, repo string, number int, labels []*github.Label) {
+	key := issueKey(org, repo, number)
+	if issue, ok := g.issues[key]; ok {
+		issue.Labels = labels
+	} else {
+		g.issues[key] = &github.Issue{Number: github.Int(number), State: github.String("open"), Labels: labels}
+	}
+	if pr, ok := g.prs[key]; ok {
+		pr.Labels = labels
+	}
+}
+
+func (g *FakeGitHub) record(org, repo string, number int, action, details string) {
+	if g.recorder == nil {
+		return
+	}
+	g.recorder.Record(&RecordedAction{
+		Time:    time.Now(),
+		Org:     org,
+		Repo:    repo,
+		Number:  number,
+		Action:  action,
+		Details: details,
+	})
+}
+
+// ValidateSignature accepts all the webhooks.
+func (g *FakeGitHub) ValidateSignature(receivedHash []string, bodyBuffer []byte) error {
+	return nil
+}
+
+func (g *FakeGitHub) CreateComment(org, repo string, number int, comment string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.nextID++
+	now := time.Now()
+	key := issueKey(org, repo, number)
+	g.comments[key] = append(g.comments[key], &github.IssueComment{
+		ID:        github.Int64(g.nextID),
+		Body:      github.String(comment),
+		User:      &github.User{Login: github.String("chewbacca"), Type: github.String("Bot")},
+		CreatedAt: &now,
+	})
+	g.record(org, repo, number, "CreateComment", comment)
+	return nil
+}
+
+func (g *FakeGitHub) CreateLabel(org, repo string, label github.Label) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.repoLabel(org, repo, label.GetName(), &label)
+	g.record(org, repo, 0, "CreateLabel", label.GetName())
+	return nil
+}
+
+func (g *FakeGitHub) AddLabels(org, repo string, number int, labels []string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	current := g.issueLabels(org, repo, number)
+	names := make(map[string]bool)
+	for _, label := range current {
+		names[strings.ToLower(label.GetName())] = true
+	}
+	for _, name := range labels {
+		if !names[strings.ToLower(name)] {
+			current = append(current, g.repoLabel(org, repo, name, nil))
+			names[strings.ToLower(name)] = true
+		}
+	}
+	g.setIssueLabels(org, repo, number, current)
+	g.record(org, repo, number, "AddLabels", strings.Join(labels, ", "))
+	return nil
+}
+
+func (g *FakeGitHub) RemoveLabel(org, repo string, number int, label string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var labels []*github.Label
+	for _, l := range g.issueLabels(org, repo, number) {
+		if !strings.EqualFold(l.GetName(), label) {
+			labels = append(labels, l)
+		}
+	}
+	g.setIssueLabels(org, repo, number, labels)
+	g.record(org, repo, number, "RemoveLabel", label)
+	return nil
+}
+
+func (g *FakeGitHub) issueLabels(org, repo string, number int) []*github.Label {
+	if issue, ok := g.issues[issueKey(org, repo, number)]; ok {
+		return append([]*github.Label(nil), issue.Labels...)
+	}
+	return nil
+}
+
+func (g *FakeGitHub) GetIssueLabels(org, repo string, number int) ([]*github.Label, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.issueLabels(org, repo, number), nil
+}
+
+func (g *FakeGitHub) ListIssueComments(org, repo string, number int) ([]*github.IssueComment, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]*github.IssueComment(nil), g.comments[issueKey(org, repo, number)]...), nil
+}
+
+func (g *FakeGitHub) GetComments(org, repo string, number int) ([]*github.IssueComment, error) {
+	return g.ListIssueComments(org, repo, number)
+}
+
+func (g *FakeGitHub) IsMember(org, user string) (bool, error) {
+	return g.Members.Has(user), nil
+}
+
+func (g *FakeGitHub) SetStatus(org, repo, sha, state, message string) error {
+	g.record(org, repo, 0, "SetStatus", fmt.Sprintf("%s on %s: %s", state, sha, message))
+	return nil
+}
+
+func (g *FakeGitHub) SupportsChecks() bool {
+	return g.Checks
+}
+
+func checkRunKey(org, repo, sha, name string) string {
+	return repoKey(org, repo) + "@" + sha + "/" + name
+}
+
+func (g *FakeGitHub) CreateCheckRun(org, repo string, checkRun github.CreateCheckRunOptions) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.nextID++
+	g.checkRuns[checkRunKey(org, repo, checkRun.HeadSHA, checkRun.Name)] = &github.CheckRun{
+		ID:         github.Int64(g.nextID),
+		Name:       github.String(checkRun.Name),
+		HeadSHA:    github.String(checkRun.HeadSHA),
+		Status:     checkRun.Status,
+		Conclusion: checkRun.Conclusion,
+		Output:     checkRun.Output,
+	}
+	g.record(org, repo, 0, "CreateCheckRun", fmt.Sprintf("%s %s", checkRun.Name, checkRun.GetConclusion()))
+	return nil
+}
+
+func (g *FakeGitHub) GetCheckRun(org, repo, sha, name string) (*github.CheckRun, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.checkRuns[checkRunKey(org, repo, sha, name)], nil
+}
+
+func (g *FakeGitHub) UpdateCheckRun(org, repo string, id int64, checkRun github.UpdateCheckRunOptions) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for key, existing := range g.checkRuns {
+		if existing.GetID() == id && strings.HasPrefix(key, repoKey(org, repo)+"@") {
+			existing.Status = checkRun.Status
+			existing.Conclusion = checkRun.Conclusion
+			existing.Output = checkRun.Output
+		}
+	}
+	g.record(org, repo, 0, "UpdateCheckRun", fmt.Sprintf("%s %s", checkRun.Name, checkRun.GetConclusion()))
+	return nil
+}
+
+func (g *FakeGitHub) GetPullRequest(org, repo string, number int) (*github.PullRequest, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	pr, ok := g.prs[issueKey(org, repo, number)]
+	if !ok {
+		return nil, errors.Errorf("Unable to get the pull request %s", issueKey(org, repo, number))
+	}
+	return pr, nil
+}
+
+func (g *FakeGitHub) ListOpenIssues(org, repo string) ([]*github.Issue, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var issues []*github.Issue
+	for key, issue := range g.issues {
+		if strings.HasPrefix(key, repoKey(org, repo)+"#") && issue.GetState() == "open" {
+			issues = append(issues, issue)
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		return issues[i].GetNumber() < issues[j].GetNumber()
+	})
+	return issues, nil
+}
+
+func (g *FakeGitHub) CloseIssue(org, repo string, number int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if issue, ok := g.issues[issueKey(org, repo, number)]; ok {
+		issue.State = github.String("closed")
+	}
+	if pr, ok := g.prs[issueKey(org, repo, number)]; ok {
+		pr.State = github.String("closed")
+	}
+	g.record(org, repo, number, "CloseIssue", "")
+	return nil
+}
+
+func (g *FakeGitHub) CountMergedPullRequests(org, repo, author string) (int, error) {
+	return 0, nil
+}
+
+func (g *FakeGitHub) CreatePullRequest(org, repo, title, head, base, body string) (*github.PullRequest, error) {
+	g.record(org, repo, 0, "CreatePullRequest", fmt.Sprintf("%s from %s to %s", title, head, base))
+	return &github.PullRequest{Title: github.String(title)}, nil
+}
+
+func (g *FakeGitHub) EnsureFork(org, repo, owner string) error {
+	g.record(org, repo, 0, "EnsureFork", owner)
+	return nil
+}
+
+// Token fails since the fake can't authenticate the git operations.
+func (g *FakeGitHub) Token(org string) (string, error) {
+	return "", errors.New("no token for the fake GitHub")
+}
+
+func (g *FakeGitHub) CompareCommits(org, repo, base, head string) ([]*github.RepositoryCommit, error) {
+	return nil, nil
+}
+
+func (g *FakeGitHub) ListPullRequestsWithCommit(org, repo, sha string) ([]*github.PullRequest, error) {
+	return nil, nil
+}
+
+func (g *FakeGitHub) ListPullRequests(org, repo, base string) ([]*github.PullRequest, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var prs []*github.PullRequest
+	for key, pr := range g.prs {
+		if strings.HasPrefix(key, repoKey(org, repo)+"#") && pr.GetState() == "open" && (base == "" || pr.GetBase().GetRef() == base) {
+			prs = append(prs, pr)
+		}
+	}
+	sort.Slice(prs, func(i, j int) bool {
+		return prs[i].GetNumber() < prs[j].GetNumber()
+	})
+	return prs, nil
+}
+
+func (g *FakeGitHub) ListPullRequestFiles(org, repo string, number int) ([]*github.CommitFile, error) {
+	return nil, nil
+}
+
+func (g *FakeGitHub) RequestReviewers(org, repo string, number int, reviewers []string) error {
+	g.record(org, repo, number, "RequestReviewers", strings.Join(reviewers, ", "))
+	return nil
+}
+
+func (g *FakeGitHub) ListRepoLabels(org, repo string) ([]*github.Label, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var labels []*github.Label
+	for _, label := range g.repoLabels[repoKey(org, repo)] {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].GetName() < labels[j].GetName()
+	})
+	return labels, nil
+}
+
+func (g *FakeGitHub) EditLabel(org, repo, name string, label github.Label) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.repoLabels[repoKey(org, repo)], strings.ToLower(name))
+	g.repoLabel(org, repo, label.GetName(), &label)
+	g.record(org, repo, 0, "EditLabel", fmt.Sprintf("%s to %s", name, label.GetName()))
+	return nil
+}
+
+func (g *FakeGitHub) DeleteLabel(org, repo, name string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.repoLabels[repoKey(org, repo)], strings.ToLower(name))
+	g.record(org, repo, 0, "DeleteLabel", name)
+	return nil
+}
+
+func (g *FakeGitHub) ListOrgRepos(org string) ([]*github.Repository, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var repos []*github.Repository
+	for key := range g.repoLabels {
+		if name := strings.TrimPrefix(key, strings.ToLower(org)+"/"); name != key {
+			repos = append(repos, &github.Repository{Name: github.String(name)})
+		}
+	}
+	return repos, nil
+}
+
+// GetFileContent returns the file of Files with the path, whatever the repository.
+func (g *FakeGitHub) GetFileContent(org, repo, path, ref string) ([]byte, error) {
+	return g.Files[path], nil
+}
+
+func (g *FakeGitHub) SetInstallation(org string, installationID int64) {}
+
+func (g *FakeGitHub) RemoveInstallation(org string) {}
+
+func (g *FakeGitHub) GetRateLimit() (*github.Rate, error) {
+	return &github.Rate{Limit: 5000, Remaining: 5000}, nil
+}
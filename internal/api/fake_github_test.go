@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/config"
+	"github.com/mattermost/chewbacca/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestReplayWithFakeGitHub(t *testing.T) {
+	logger := log.New()
+	recorder := NewRecorder(100)
+	fake := NewFakeGitHub(recorder)
+	fake.Files[config.FileName] = []byte("plugins: [release-notes]")
+	c := &Context{
+		GitHub: fake,
+		Config: config.NewStore(fake, time.Minute, logger),
+		Logger: logger,
+	}
+
+	payload := []byte(`{
+		"action": "opened",
+		"number": 7,
+		"pull_request": {"number": 7, "state": "open", "body": "No release note here.", "user": {"login": "alice"}, "head": {"ref": "fix-typo"}},
+		"repository": {"name": "repo", "owner": {"login": "org"}}
+	}`)
+	fake.Observe(model.EventTypePullRequest, payload)
+	if _, err := ProcessEvent(c, model.EventTypePullRequest, payload, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	labels, _ := fake.GetIssueLabels("org", "repo", 7)
+	if len(labels) != 1 || labels[0].GetName() != ReleaseNoteLabelNeeded {
+		t.Fatalf("expected the %s label, got %v", ReleaseNoteLabelNeeded, labels)
+	}
+	actions := recorder.Actions("org", "repo")
+	if len(actions) != 2 || actions[0].Action != "CreateComment" || actions[1].Action != "AddLabels" {
+		t.Fatalf("expected the recorded changes, got %+v", actions)
+	}
+}
+
+// newTestContext returns a context using a fake GitHub, with the plugins enabled in the
+// repository configuration and the logins as org members.
+func newTestContext(plugins string, members ...string) (*Context, *FakeGitHub, *Recorder) {
+	logger := log.New()
+	recorder := NewRecorder(100)
+	fake := NewFakeGitHub(recorder)
+	fake.Members.Insert(members...)
+	fake.Files[config.FileName] = []byte("plugins: [" + plugins + "]")
+	c := &Context{
+		GitHub: fake,
+		Config: config.NewStore(fake, time.Minute, logger),
+		Logger: logger,
+	}
+	return c, fake, recorder
+}
+
+// testLabels returns the JSON array of the labels of a webhook payload.
+func testLabels(labels []string) string {
+	var names []string
+	for _, label := range labels {
+		names = append(names, fmt.Sprintf(`{"name": %q}`, label))
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+// testPullRequestPayload returns a pull_request webhook of the PR#7 of org/repo. Like
+// on GitHub, the authors whose login ends with [bot] are bots.
+func testPullRequestPayload(action, author string, labels ...string) []byte {
+	userType := "User"
+	if strings.HasSuffix(author, "[bot]") {
+		userType = "Bot"
+	}
+	return []byte(fmt.Sprintf(`{
+		"action": %q,
+		"number": 7,
+		"pull_request": {"number": 7, "state": "open", "user": {"login": %q, "type": %q}, "labels": %s, "head": {"ref": "fix", "sha": "abc"}, "base": {"ref": "master"}},
+		"repository": {"name": "repo", "owner": {"login": "org"}}
+	}`, action, author, userType, testLabels(labels)))
+}
+
+// testCommentID is the ID of the latest comment of testCommentPayload.
+var testCommentID int64
+
+// testCommentPayload returns an issue_comment webhook of a new comment on the PR#7 of
+// org/repo.
+func testCommentPayload(commenter, author, body string, labels ...string) []byte {
+	testCommentID++
+	return []byte(fmt.Sprintf(`{
+		"action": "created",
+		"issue": {"number": 7, "state": "open", "user": {"login": %q}, "labels": %s, "pull_request": {"url": "https://api.github.com/repos/org/repo/pulls/7"}},
+		"comment": {"id": %d, "body": %q, "user": {"login": %q}},
+		"repository": {"name": "repo", "owner": {"login": "org"}}
+	}`, author, testLabels(labels), testCommentID, body, commenter))
+}
+
+// processTestEvent seeds the fake GitHub with the webhook and processes it.
+func processTestEvent(t *testing.T, c *Context, fake *FakeGitHub, eventType string, payload []byte) error {
+	t.Helper()
+	if !json.Valid(payload) {
+		t.Fatalf("invalid payload %s", payload)
+	}
+	fake.Observe(eventType, payload)
+	_, err := ProcessEvent(c, eventType, payload, nil)
+	return err
+}
+
+// actionNames returns the names of the recorded actions.
+func actionNames(actions []*RecordedAction) []string {
+	var names []string
+	for _, action := range actions {
+		names = append(names, action.Action)
+	}
+	return names
+}
+
+// labelNames returns the names of the labels of the PR#7 of org/repo.
+func labelNames(fake *FakeGitHub) []string {
+	labels, _ := fake.GetIssueLabels("org", "repo", 7)
+	var names []string
+	for _, label := range labels {
+		names = append(names, label.GetName())
+	}
+	return names
+}
@@ -6,10 +6,13 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/mattermost/chewbacca/internal/metrics"
+	"github.com/mattermost/chewbacca/internal/queue"
 	"github.com/mattermost/chewbacca/model"
 
-	"github.com/google/go-github/v31/github"
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 // initGitHubWebhook registers webhook endpoints on the given router.
@@ -26,25 +29,34 @@ func initGitHubWebhook(apiRouter *mux.Router, context *Context) {
 func handleReceiveWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
 	buf, _ := io.ReadAll(r.Body)
 
-	receivedHash := strings.SplitN(r.Header.Get("X-Hub-Signature"), "=", 2)
-	if receivedHash[0] != "sha1" {
-		c.Logger.Error("invalid webhook hash signature: SHA1")
-		w.WriteHeader(http.StatusForbidden)
-		return
+	// Prefer the SHA-256 signature, the SHA-1 one is only validated when allowed.
+	signature := r.Header.Get("X-Hub-Signature-256")
+	if signature == "" {
+		signature = r.Header.Get("X-Hub-Signature")
 	}
 
-	err := c.GitHub.ValidateSignature(receivedHash, buf)
+	err := c.GitHub.ValidateSignature(strings.SplitN(signature, "=", 2), buf)
 	if err != nil {
-		c.Logger.WithError(err)
+		c.Logger.WithError(err).Error("invalid webhook signature")
 		w.WriteHeader(http.StatusForbidden)
 		return
 	}
 
-	var org, repo string
-	var number int
 	eventType := r.Header.Get("X-GitHub-Event")
+	deliveryID := r.Header.Get("X-GitHub-Delivery")
+	c.Logger = c.Logger.WithFields(log.Fields{
+		"event":    eventType,
+		"delivery": deliveryID,
+	})
+
+	var action string
+	if webhook := model.WebhookPayloadFromJSON(bytes.NewReader(buf)); webhook != nil {
+		action = webhook.Action
+	}
+	metrics.WebhooksReceived.WithLabelValues(eventType, action).Inc()
+
 	switch eventType {
-	case "ping":
+	case model.EventTypePing:
 		pingEvent := model.PingEventFromJSON(io.NopCloser(bytes.NewBuffer(buf)))
 		if pingEvent == nil {
 			c.Logger.WithField("hookID", pingEvent.GetHookID()).Info("ping event")
@@ -53,44 +65,154 @@ func handleReceiveWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
 		}
 		w.WriteHeader(http.StatusAccepted)
 		return
-	case "pull_request":
-		event := model.PullRequestEventFromJSON(io.NopCloser(bytes.NewBuffer(buf)))
-		c.Logger = c.Logger.WithField("pr", event.GetNumber())
-		c.Logger.WithField("action", event.GetAction()).Info("pull request event")
-		org = event.GetRepo().GetOwner().GetLogin()
-		repo = event.GetRepo().GetName()
-		number = event.GetNumber()
-		handlePullRequestEvent(c, event)
-	case "issue_comment":
-		event := model.IssueCommentEventFromJSON(io.NopCloser(bytes.NewBuffer(buf)))
-		c.Logger = c.Logger.WithField("issue", event.GetIssue().GetNumber())
-		c.Logger.Info("issue comment event")
-		handleIssueCommentEvent(c, event)
-		org = event.GetRepo().GetOwner().GetLogin()
-		repo = event.GetRepo().GetName()
-		number = event.GetIssue().GetNumber()
-		if !event.GetIssue().IsPullRequest() {
-			// if not a pull request dont need to set the status
-			w.WriteHeader(http.StatusAccepted)
-			return
-		}
+	case model.EventTypePullRequest, model.EventTypeIssueComment, model.EventTypeCheckRun, model.EventTypePush,
+		model.EventTypeInstallation, model.EventTypeInstallationRepositories:
 	default:
 		c.Logger.Info("other events not implemented")
 		w.WriteHeader(http.StatusNotImplemented)
 		return
 	}
 
-	go checkBlockStatus(c, org, repo, number)
+	if deliveryID != "" && c.Deliveries != nil && c.Deliveries.Seen(deliveryID) {
+		c.Logger.Info("skipping an already received delivery")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if c.Archive != nil {
+		if err := c.Archive.Write(r.Header, buf); err != nil {
+			c.Logger.WithError(err).Warn("failed to archive the webhook")
+		}
+	}
+
+	if c.Queue == nil {
+		if _, err = ProcessEvent(c, eventType, buf, nil); err != nil {
+			c.Logger.WithError(err).Error("failed to process the event")
+		}
+	} else if err = c.Queue.Enqueue(eventType, deliveryID, buf); err != nil {
+		c.Logger.WithError(err).Error("failed to queue the event")
+		if deliveryID != "" && c.Deliveries != nil {
+			c.Deliveries.Forget(deliveryID)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
 }
 
-func handlePullRequestEvent(c *Context, pr *github.PullRequestEvent) {
-	handleReleaseNotesPR(c, pr)
+// NewJobHandler returns the queue handler processing the queued webhook events.
+func NewJobHandler(context *Context) queue.Handler {
+	return func(job *queue.Job) error {
+		c := context.Clone()
+		c.RequestID = model.NewID()
+		c.Logger = c.Logger.WithFields(log.Fields{
+			"request":  c.RequestID,
+			"job":      job.ID,
+			"event":    job.EventType,
+			"delivery": job.DeliveryID,
+		})
+
+		failed, err := ProcessEvent(c, job.EventType, job.Payload, job.Plugins)
+		if len(failed) > 0 {
+			// Only retry the plugins which failed.
+			job.Plugins = failed
+		}
+		return err
+	}
 }
 
-func handleIssueCommentEvent(c *Context, issueComment *github.IssueCommentEvent) {
-	handleReleaseNotesComment(c, issueComment)
-	handleCommentLabel(c, issueComment)
+// ProcessEvent dispatches a webhook event to the plugins. When plugins is not empty,
+// only those plugins are run. The names of the plugins which failed are returned.
+func ProcessEvent(c *Context, eventType string, payload []byte, plugins []string) ([]string, error) {
+	registerInstallation(c, payload)
+
+	e := &Event{Type: eventType}
+	switch eventType {
+	case model.EventTypeInstallation:
+		event := model.InstallationEventFromJSON(bytes.NewReader(payload))
+		if event == nil {
+			return nil, errors.New("failed to decode the installation event")
+		}
+		return nil, handleInstallationEvent(c, event)
+	case model.EventTypeInstallationRepositories:
+		event := model.InstallationRepositoriesEventFromJSON(bytes.NewReader(payload))
+		if event == nil {
+			return nil, errors.New("failed to decode the installation repositories event")
+		}
+		return nil, handleInstallationRepositoriesEvent(c, event)
+	case model.EventTypePullRequest:
+		event := model.PullRequestEventFromJSON(bytes.NewReader(payload))
+		if event == nil {
+			return nil, errors.New("failed to decode the pull request event")
+		}
+		c.Logger = c.Logger.WithField("pr", event.GetNumber())
+		c.Logger.WithField("action", event.GetAction()).Info("pull request event")
+		e.Action = event.GetAction()
+		e.Org = event.GetRepo().GetOwner().GetLogin()
+		e.Repo = event.GetRepo().GetName()
+		e.Number = event.GetNumber()
+		e.IsPullRequest = true
+		e.PullRequest = event
+	case model.EventTypeIssueComment:
+		event := model.IssueCommentEventFromJSON(bytes.NewReader(payload))
+		if event == nil {
+			return nil, errors.New("failed to decode the issue comment event")
+		}
+		c.Logger = c.Logger.WithField("issue", event.GetIssue().GetNumber())
+		c.Logger.Info("issue comment event")
+		e.Action = event.GetAction()
+		e.Org = event.GetRepo().GetOwner().GetLogin()
+		e.Repo = event.GetRepo().GetName()
+		e.Number = event.GetIssue().GetNumber()
+		e.IsPullRequest = event.GetIssue().IsPullRequest()
+		e.IssueComment = event
+	case model.EventTypeCheckRun:
+		event := model.CheckRunEventFromJSON(bytes.NewReader(payload))
+		if event == nil {
+			return nil, errors.New("failed to decode the check run event")
+		}
+		c.Logger.WithFields(log.Fields{
+			"action":    event.GetAction(),
+			"check_run": event.GetCheckRun().GetName(),
+		}).Info("check run event")
+		e.Action = event.GetAction()
+		e.Org = event.GetRepo().GetOwner().GetLogin()
+		e.Repo = event.GetRepo().GetName()
+		if prs := event.GetCheckRun().PullRequests; len(prs) > 0 {
+			e.Number = prs[0].GetNumber()
+			e.IsPullRequest = true
+			c.Logger = c.Logger.WithField("pr", e.Number)
+		}
+		e.CheckRun = event
+	case model.EventTypePush:
+		event := model.PushEventFromJSON(bytes.NewReader(payload))
+		if event == nil {
+			return nil, errors.New("failed to decode the push event")
+		}
+		c.Logger.WithField("ref", event.GetRef()).Info("push event")
+		e.Org = event.GetRepo().GetOwner().GetLogin()
+		if e.Org == "" {
+			e.Org = event.GetRepo().GetOwner().GetName()
+		}
+		e.Repo = event.GetRepo().GetName()
+		e.Push = event
+	default:
+		return nil, errors.Errorf("unsupported event type %s", eventType)
+	}
+
+	c.enableRepoDryRun(e.Org, e.Repo)
+
+	registry := c.Plugins
+	if registry == nil {
+		registry = NewDefaultRegistry()
+	}
+
+	failed := registry.Dispatch(c, e, plugins)
+	if len(failed) > 0 {
+		return failed, errors.Errorf("plugins failed: %s", strings.Join(failed, ", "))
+	}
+
+	return nil, nil
 }
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// readinessCacheDuration limits how often the readiness probes hit the GitHub API.
+const readinessCacheDuration = 15 * time.Second
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// initHealth registers the liveness and readiness endpoints on the given router.
+func initHealth(rootRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	checker := &readinessChecker{}
+	rootRouter.Handle("/healthz", addContext(handleHealthz)).Methods("GET")
+	rootRouter.Handle("/readyz", addContext(checker.handleReadyz)).Methods("GET")
+}
+
+// handleHealthz responds to GET /healthz, reporting the server is alive.
+func handleHealthz(c *Context, w http.ResponseWriter, r *http.Request) {
+	outputJSON(c, w, healthResponse{Status: "ok"})
+}
+
+type readinessChecker struct {
+	mu      sync.Mutex
+	checked time.Time
+	result  healthResponse
+}
+
+// handleReadyz responds to GET /readyz, reporting whether the server is able to
+// process the webhooks.
+func (r *readinessChecker) handleReadyz(c *Context, w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	if time.Since(r.checked) > readinessCacheDuration {
+		r.result = checkReadiness(c)
+		r.checked = time.Now()
+	}
+	result := r.result
+	r.mu.Unlock()
+
+	if result.Status != "ok" {
+		c.Logger.WithField("checks", result.Checks).Warn("not ready")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	outputJSON(c, w, result)
+}
+
+func checkReadiness(c *Context) healthResponse {
+	result := healthResponse{Status: "ok", Checks: make(map[string]string)}
+	fail := func(check, reason string) {
+		result.Status = "unavailable"
+		result.Checks[check] = reason
+	}
+
+	rate, err := c.GitHub.GetRateLimit()
+	switch {
+	case err != nil:
+		fail("github", err.Error())
+	case rate != nil && rate.Remaining == 0 && rate.Reset.After(time.Now()):
+		result.Checks["github"] = "ok"
+		fail("rate_limit", fmt.Sprintf("exhausted until %s", rate.Reset.Format(time.RFC3339)))
+	default:
+		result.Checks["github"] = "ok"
+		result.Checks["rate_limit"] = "ok"
+	}
+
+	if c.Queue != nil {
+		depth, err := c.Queue.Depth()
+		switch {
+		case err != nil:
+			fail("queue", err.Error())
+		case c.MaxQueueDepth > 0 && depth > c.MaxQueueDepth:
+			fail("queue", fmt.Sprintf("%d events waiting, more than %d", depth, c.MaxQueueDepth))
+		default:
+			result.Checks["queue"] = "ok"
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mattermost/chewbacca/internal/utils"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
+)
+
+const holdLabel = "do-not-merge/hold"
+
+var (
+	holdRe       = regexp.MustCompile(`(?mi)^/hold(?:[ \t]+(.*?))?[ \t]*$`)
+	holdCancelRe = regexp.MustCompile(`(?mi)^/hold\s+cancel\s*$`)
+)
+
+// hold is a hold placed on a pull request with the /hold command.
+type hold struct {
+	User   string
+	Reason string
+}
+
+// holdPlugin handles the /hold and /hold cancel commands.
+type holdPlugin struct{}
+
+func (p *holdPlugin) Name() string {
+	return "hold"
+}
+
+func (p *holdPlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypeIssueComment: {model.IssueCommentActionCreated},
+	}
+}
+
+func (p *holdPlugin) Handle(c *Context, e *Event) error {
+	if !e.IsPullRequest {
+		return nil
+	}
+
+	comment := e.IssueComment.GetComment()
+	body := comment.GetBody()
+	commenter := comment.GetUser().GetLogin()
+	hasHold := utils.HasLabel(holdLabel, e.IssueComment.GetIssue().Labels)
+
+	if holdCancelRe.MatchString(body) {
+		if !hasHold {
+			return nil
+		}
+
+		comments, err := c.GitHub.ListIssueComments(e.Org, e.Repo, e.Number)
+		if err != nil {
+			return errors.Wrap(err, "failed to list the comments")
+		}
+		// The hold is the one placed before this comment.
+		var previous []*github.IssueComment
+		for _, ic := range comments {
+			if ic.GetID() != comment.GetID() {
+				previous = append(previous, ic)
+			}
+		}
+		current := currentHold(previous)
+		isAuthor := utils.IsAuthor(e.IssueComment.GetIssue().GetUser().GetLogin(), commenter)
+		isHolder := current != nil && utils.IsAuthor(current.User, commenter)
+		if !isAuthor && !isHolder {
+			isMember, err := c.GitHub.IsMember(e.Org, commenter)
+			if err != nil {
+				return errors.Wrap(err, "failed to get the membership")
+			}
+			if !isMember {
+				c.Logger.Info("not member")
+				msg := "only the org members or the PR author can release the hold."
+				if current != nil {
+					msg = fmt.Sprintf("only the org members or the PR author can release the hold placed by @%s.", current.User)
+				}
+				return c.GitHub.CreateComment(e.Org, e.Repo, e.Number, utils.FormatICResponse(comment, msg))
+			}
+		}
+
+		c.Logger.Info("removing hold label")
+		return c.GitHub.RemoveLabel(e.Org, e.Repo, e.Number, holdLabel)
+	}
+
+	match := holdRe.FindStringSubmatch(body)
+	if match == nil {
+		return nil
+	}
+
+	if !hasHold {
+		c.Logger.Info("adding hold label")
+		if err := c.GitHub.AddLabels(e.Org, e.Repo, e.Number, []string{holdLabel}); err != nil {
+			return errors.Wrap(err, "failed to add the hold label")
+		}
+	}
+
+	return c.GitHub.CreateComment(e.Org, e.Repo, e.Number, utils.FormatICResponse(comment, formatHold(&hold{User: commenter, Reason: match[1]})+" Comment `/hold cancel` to release it."))
+}
+
+// currentHold returns the hold placed by the latest /hold command which wasn't
+// cancelled afterwards, or nil if there is none.
+func currentHold(comments []*github.IssueComment) *hold {
+	var h *hold
+	for _, comment := range comments {
+		switch body := comment.GetBody(); {
+		case holdCancelRe.MatchString(body):
+			h = nil
+		case holdRe.MatchString(body):
+			h = &hold{
+				User:   comment.GetUser().GetLogin(),
+				Reason: holdRe.FindStringSubmatch(body)[1],
+			}
+		}
+	}
+
+	return h
+}
+
+// formatHold describes who placed the hold and why.
+func formatHold(h *hold) string {
+	if h.Reason == "" {
+		return fmt.Sprintf("Held by @%s.", h.User)
+	}
+	return fmt.Sprintf("Held by @%s: %s.", h.User, strings.TrimSuffix(h.Reason, "."))
+}
+
+// describeHold explains the hold label blocking the merge with who placed the hold
+// and why.
+func describeHold(c *Context, org, repo string, number int, reasons []blockReason) {
+	for i := range reasons {
+		if !strings.EqualFold(reasons[i].Label, holdLabel) {
+			continue
+		}
+
+		comments, err := c.GitHub.ListIssueComments(org, repo, number)
+		if err != nil {
+			c.Logger.WithError(err).Errorf("failed to list the comments on PR #%d", number)
+			return
+		}
+		h := currentHold(comments)
+		if h == nil {
+			return
+		}
+
+		reasons[i].Status = formatHold(h)
+		reasons[i].Message = formatHold(h) + " " + blockingLabelMessage(holdLabel)
+		return
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mattermost/chewbacca/model"
+)
+
+func TestHoldCancel(t *testing.T) {
+	testCases := []struct {
+		name       string
+		holder     string
+		commenter  string
+		wantLabels []string
+		wantCalls  []string
+	}{
+		{"hold placed by the commenter", "mallory", "mallory", nil, []string{"RemoveLabel"}},
+		{"hold placed by another user", "bob", "mallory", []string{holdLabel}, []string{"CreateComment"}},
+		{"unknown hold released by a non member", "", "mallory", []string{holdLabel}, []string{"CreateComment"}},
+		{"unknown hold released by a member", "", "bob", nil, []string{"RemoveLabel"}},
+		{"unknown hold released by the author", "", "alice", nil, []string{"RemoveLabel"}},
+		{"hold released by the author", "bob", "alice", nil, []string{"RemoveLabel"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, fake, recorder := newTestContext("hold", "bob")
+			// Without a /hold comment, the label was added by hand.
+			if tc.holder != "" {
+				fake.Observe(model.EventTypeIssueComment, testCommentPayload(tc.holder, "alice", "/hold", holdLabel))
+			}
+
+			payload := testCommentPayload(tc.commenter, "alice", "/hold cancel", holdLabel)
+			if err := processTestEvent(t, c, fake, model.EventTypeIssueComment, payload); err != nil {
+				t.Fatal(err)
+			}
+
+			if labels := labelNames(fake); !reflect.DeepEqual(labels, tc.wantLabels) {
+				t.Errorf("expected the labels %v, got %v", tc.wantLabels, labels)
+			}
+			if calls := actionNames(recorder.Actions("org", "repo")); !reflect.DeepEqual(calls, tc.wantCalls) {
+				t.Errorf("expected the changes %v, got %v", tc.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestHoldAfterManyComments(t *testing.T) {
+	c, fake, recorder := newTestContext("hold", "bob")
+	for i := 0; i < 40; i++ {
+		fake.Observe(model.EventTypeIssueComment, testCommentPayload("bob", "alice", "Looks good so far."))
+	}
+	fake.Observe(model.EventTypeIssueComment, testCommentPayload("mallory", "alice", "/hold waiting for the release", holdLabel))
+
+	reasons := []blockReason{{Rule: "do-not-merge", Label: holdLabel}}
+	describeHold(c, "org", "repo", 7, reasons)
+	if reasons[0].Status != "Held by @mallory: waiting for the release." {
+		t.Fatalf("expected the hold to be described, got %q", reasons[0].Status)
+	}
+
+	payload := testCommentPayload("mallory", "alice", "/hold cancel", holdLabel)
+	if err := processTestEvent(t, c, fake, model.EventTypeIssueComment, payload); err != nil {
+		t.Fatal(err)
+	}
+	if calls := actionNames(recorder.Actions("org", "repo")); !reflect.DeepEqual(calls, []string{"RemoveLabel"}) {
+		t.Fatalf("expected the holder to release the hold, got %v", calls)
+	}
+}
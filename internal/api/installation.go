@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// registerInstallation records the app installation found in a webhook payload, so the
+// events of the repository are handled with the installation token.
+func registerInstallation(c *Context, payload []byte) {
+	webhook := model.WebhookPayloadFromJSON(bytes.NewReader(payload))
+	if webhook == nil || webhook.Installation == nil || webhook.Repository == nil {
+		return
+	}
+
+	c.GitHub.SetInstallation(webhook.Repository.GetOwner().GetLogin(), webhook.Installation.GetID())
+}
+
+// handleInstallationEvent keeps track of the organizations and users the app is
+// installed on.
+func handleInstallationEvent(c *Context, event *github.InstallationEvent) error {
+	account := event.GetInstallation().GetAccount().GetLogin()
+	c.Logger.WithFields(log.Fields{
+		"action":  event.GetAction(),
+		"account": account,
+	}).Info("installation event")
+
+	switch event.GetAction() {
+	case model.InstallationActionCreated, model.InstallationActionUnsuspend:
+		c.GitHub.SetInstallation(account, event.GetInstallation().GetID())
+	case model.InstallationActionDeleted, model.InstallationActionSuspend:
+		c.GitHub.RemoveInstallation(account)
+	}
+
+	return nil
+}
+
+// handleInstallationRepositoriesEvent makes sure the repositories added to an
+// installation are handled with the installation token.
+func handleInstallationRepositoriesEvent(c *Context, event *github.InstallationRepositoriesEvent) error {
+	account := event.GetInstallation().GetAccount().GetLogin()
+	if account == "" {
+		return errors.New("installation repositories event without account")
+	}
+
+	var added, removed []string
+	for _, repo := range event.RepositoriesAdded {
+		added = append(added, repo.GetFullName())
+	}
+	for _, repo := range event.RepositoriesRemoved {
+		removed = append(removed, repo.GetFullName())
+	}
+	c.Logger.WithFields(log.Fields{
+		"action":  event.GetAction(),
+		"account": account,
+		"added":   added,
+		"removed": removed,
+	}).Info("installation repositories event")
+
+	c.GitHub.SetInstallation(account, event.GetInstallation().GetID())
+	if c.Config != nil {
+		for _, repo := range event.RepositoriesAdded {
+			c.Config.Invalidate(account, repo.GetName())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/config"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// LabelSyncActionCreate creates a label missing from a repository.
+	LabelSyncActionCreate = "create"
+	// LabelSyncActionUpdate updates the color or description of a label.
+	LabelSyncActionUpdate = "update"
+	// LabelSyncActionRename renames a label from one of its previous names.
+	LabelSyncActionRename = "rename"
+	// LabelSyncActionDelete deletes a label which is not in the manifest.
+	LabelSyncActionDelete = "delete"
+)
+
+// LabelSyncOptions controls how the labels are synchronized.
+type LabelSyncOptions struct {
+	// DryRun only reports the actions.
+	DryRun bool
+	// Delete deletes the labels which are not in the manifest, including the labels
+	// with a previous name of a label which also exists.
+	Delete bool
+}
+
+// LabelSyncAction is a change of a repository label to match the label manifest.
+type LabelSyncAction struct {
+	Org         string   `json:"org"`
+	Repo        string   `json:"repo"`
+	Action      string   `json:"action"`
+	Label       string   `json:"label"`
+	Previous    string   `json:"previous,omitempty"`
+	Color       string   `json:"color,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Changes     []string `json:"changes,omitempty"`
+}
+
+// String describes the action in a report line.
+func (a *LabelSyncAction) String() string {
+	line := fmt.Sprintf("%s/%s: %s %s", a.Org, a.Repo, a.Action, a.Label)
+	if a.Action == LabelSyncActionRename {
+		line = fmt.Sprintf("%s/%s: %s %s to %s", a.Org, a.Repo, a.Action, a.Previous, a.Label)
+	}
+	if len(a.Changes) > 0 {
+		line += " (" + strings.Join(a.Changes, ", ") + ")"
+	}
+	return line
+}
+
+// SyncLabels creates, updates, renames and optionally deletes the labels of all the
+// repositories of the organization to match the manifest. The archived repositories
+// are skipped, and the changes are only recorded on the repositories in dry-run mode.
+func SyncLabels(c *Context, org string, manifest *config.LabelManifest, opts LabelSyncOptions) ([]*LabelSyncAction, error) {
+	repos, err := c.GitHub.ListOrgRepos(org)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the repositories of %s", org)
+	}
+
+	var actions []*LabelSyncAction
+	var errs []error
+	for _, repository := range repos {
+		if repository.GetArchived() {
+			continue
+		}
+		repo := repository.GetName()
+		repoContext := c.Clone()
+		repoContext.RequestID = c.RequestID
+		repoContext.enableRepoDryRun(org, repo)
+
+		current, err := repoContext.GitHub.ListRepoLabels(org, repo)
+		if err != nil {
+			repoContext.Logger.WithError(err).WithField("repo", repo).Error("failed to list the labels")
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, a := range planLabelSync(org, repo, manifest, current, opts.Delete) {
+			actions = append(actions, a)
+
+			logger := repoContext.Logger.WithFields(log.Fields{
+				"repo":   repo,
+				"label":  a.Label,
+				"action": a.Action,
+			})
+			if opts.DryRun {
+				logger.Info("label sync action skipped in dry-run mode")
+				continue
+			}
+
+			logger.Info("applying label sync action")
+			if err := applyLabelSyncAction(repoContext, a); err != nil {
+				logger.WithError(err).Error("failed to apply the label sync action")
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return actions, errors.Errorf("failed %d label sync actions in %s", len(errs), org)
+	}
+
+	return actions, nil
+}
+
+// planLabelSync returns the actions making the current labels of the repository match
+// the manifest. A missing label is renamed from its first previous name found in the
+// repository, so it stays on the issues and PRs, and otherwise created.
+func planLabelSync(org, repo string, manifest *config.LabelManifest, current []*github.Label, deleteUnknown bool) []*LabelSyncAction {
+	existing := make(map[string]*github.Label)
+	for _, label := range current {
+		existing[strings.ToLower(label.GetName())] = label
+	}
+	handled := make(map[string]bool)
+
+	var actions []*LabelSyncAction
+	newAction := func(action string, label config.Label) *LabelSyncAction {
+		return &LabelSyncAction{
+			Org:         org,
+			Repo:        repo,
+			Action:      action,
+			Label:       label.Name,
+			Color:       label.Color,
+			Description: label.Description,
+		}
+	}
+
+	for _, label := range manifest.Labels {
+		target, found := existing[strings.ToLower(label.Name)]
+		action := LabelSyncActionUpdate
+		if !found {
+			action = LabelSyncActionRename
+			for _, previous := range label.Previously {
+				if target, found = existing[strings.ToLower(previous)]; found {
+					break
+				}
+			}
+		}
+
+		if !found {
+			actions = append(actions, newAction(LabelSyncActionCreate, label))
+		} else {
+			handled[strings.ToLower(target.GetName())] = true
+			if changes := labelChanges(target, label); len(changes) > 0 || action == LabelSyncActionRename {
+				a := newAction(action, label)
+				a.Previous = target.GetName()
+				a.Changes = changes
+				actions = append(actions, a)
+			}
+		}
+
+	}
+
+	// The previous names left in the repository are deleted like the unknown labels.
+	if deleteUnknown {
+		for _, label := range current {
+			if !handled[strings.ToLower(label.GetName())] {
+				actions = append(actions, newAction(LabelSyncActionDelete, config.Label{Name: label.GetName()}))
+			}
+		}
+	}
+
+	return actions
+}
+
+// labelChanges describes the differences between the repository label and the label of
+// the manifest, ignoring the case of the colors.
+func labelChanges(current *github.Label, label config.Label) []string {
+	var changes []string
+	if current.GetName() != label.Name && strings.EqualFold(current.GetName(), label.Name) {
+		changes = append(changes, fmt.Sprintf("name: %q -> %q", current.GetName(), label.Name))
+	}
+	if !strings.EqualFold(current.GetColor(), label.Color) {
+		changes = append(changes, fmt.Sprintf("color: %s -> %s", current.GetColor(), label.Color))
+	}
+	if current.GetDescription() != label.Description {
+		changes = append(changes, fmt.Sprintf("description: %q -> %q", current.GetDescription(), label.Description))
+	}
+	return changes
+}
+
+func applyLabelSyncAction(c *Context, a *LabelSyncAction) error {
+	switch a.Action {
+	case LabelSyncActionCreate:
+		return c.GitHub.CreateLabel(a.Org, a.Repo, buildGhLabel(a.Label, a.Description, a.Color))
+	case LabelSyncActionUpdate, LabelSyncActionRename:
+		return c.GitHub.EditLabel(a.Org, a.Repo, a.Previous, buildGhLabel(a.Label, a.Description, a.Color))
+	case LabelSyncActionDelete:
+		return c.GitHub.DeleteLabel(a.Org, a.Repo, a.Label)
+	}
+
+	return nil
+}
+
+// LabelSyncJob periodically synchronizes the labels of the configured organizations.
+type LabelSyncJob struct {
+	context  *Context
+	orgs     []string
+	manifest *config.LabelManifest
+	opts     LabelSyncOptions
+
+	*periodicJob
+}
+
+// NewLabelSyncJob creates a job synchronizing the labels of the organizations with the
+// manifest at each interval.
+func NewLabelSyncJob(c *Context, orgs []string, manifest *config.LabelManifest, interval time.Duration, opts LabelSyncOptions) *LabelSyncJob {
+	j := &LabelSyncJob{
+		context:  c,
+		orgs:     orgs,
+		manifest: manifest,
+		opts:     opts,
+	}
+	j.periodicJob = newPeriodicJob(interval, j.run)
+	return j
+}
+
+func (j *LabelSyncJob) run() {
+	for _, org := range j.orgs {
+		c := j.context.Clone()
+		c.RequestID = model.NewID()
+		c.Logger = c.Logger.WithFields(log.Fields{
+			"request": c.RequestID,
+			"org":     org,
+			"dry_run": j.opts.DryRun,
+		})
+
+		actions, err := SyncLabels(c, org, j.manifest, j.opts)
+		if err != nil {
+			c.Logger.WithError(err).WithField("actions", len(actions)).Error("failed to sync the labels")
+			continue
+		}
+		c.Logger.WithField("actions", len(actions)).Info("label sync done")
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/mattermost/chewbacca/internal/config"
+
+	"github.com/google/go-github/v31/github"
+)
+
+func TestPlanLabelSync(t *testing.T) {
+	manifest := &config.LabelManifest{
+		Labels: []config.Label{
+			{Name: "kind/bug", Color: "e11d21", Description: "A bug."},
+			{Name: "kind/feature", Color: "c7def8", Description: "A feature."},
+			{Name: "kind/cleanup", Color: "c7def8", Previously: []string{"cleanup"}},
+			{Name: "kind/design", Color: "c7def8", Previously: []string{"design"}},
+			{Name: "triage/accepted", Color: "8fc951"},
+		},
+	}
+	current := []*github.Label{
+		{Name: github.String("kind/bug"), Color: github.String("E11D21"), Description: github.String("A bug.")},
+		{Name: github.String("Kind/Feature"), Color: github.String("000000"), Description: github.String("A feature.")},
+		{Name: github.String("cleanup"), Color: github.String("c7def8")},
+		{Name: github.String("kind/design"), Color: github.String("c7def8")},
+		{Name: github.String("design"), Color: github.String("c7def8")},
+		{Name: github.String("wontfix"), Color: github.String("ffffff")},
+	}
+
+	tests := []struct {
+		name          string
+		deleteUnknown bool
+		expected      []string
+	}{
+		{
+			name: "without delete",
+			expected: []string{
+				`org/repo: update kind/feature (name: "Kind/Feature" -> "kind/feature", color: 000000 -> c7def8)`,
+				"org/repo: rename cleanup to kind/cleanup",
+				"org/repo: create triage/accepted",
+			},
+		},
+		{
+			name:          "with delete",
+			deleteUnknown: true,
+			expected: []string{
+				`org/repo: update kind/feature (name: "Kind/Feature" -> "kind/feature", color: 000000 -> c7def8)`,
+				"org/repo: rename cleanup to kind/cleanup",
+				"org/repo: create triage/accepted",
+				"org/repo: delete design",
+				"org/repo: delete wontfix",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := planLabelSync("org", "repo", manifest, current, tt.deleteUnknown)
+			if len(actions) != len(tt.expected) {
+				t.Fatalf("expected %d actions, got %v", len(tt.expected), actions)
+			}
+			for i, action := range actions {
+				if action.String() != tt.expected[i] {
+					t.Errorf("expected the action %q, got %q", tt.expected[i], action.String())
+				}
+			}
+		})
+	}
+}
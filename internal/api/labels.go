@@ -9,6 +9,7 @@ import (
 	"github.com/mattermost/chewbacca/model"
 
 	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -19,24 +20,27 @@ var (
 	customRemoveLabelRegex = regexp.MustCompile(`(?m)^/remove-label\s*(.*?)\s*$`)
 )
 
-func handleCommentLabel(c *Context, e *github.IssueCommentEvent) {
-	c.Logger.Infof("Starting Label section")
-	if model.IssueCommentActionDeleted == e.GetAction() || !e.GetIssue().IsPullRequest() {
-		return
+// labelPlugin handles the /kind, /priority and /label commands.
+type labelPlugin struct{}
+
+func (p *labelPlugin) Name() string {
+	return "label"
+}
+
+func (p *labelPlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypeIssueComment: {model.IssueCommentActionCreated, model.IssueCommentActionEdited},
 	}
+}
+
+func (p *labelPlugin) Handle(c *Context, e *Event) error {
+	return handleCommentLabel(c, e.IssueComment)
+}
 
-	additionalLabels := []string{
-		"kind/bug",
-		"kind/feature",
-		"kind/cleanup",
-		"kind/api-change",
-		"kind/design",
-		"kind/regression",
-		"kind/documentation",
-		"kind/design",
-		"priority/critical-urgent",
-		"priority/important-longterm",
-		"priority/important-soon",
+func handleCommentLabel(c *Context, e *github.IssueCommentEvent) error {
+	c.Logger.Infof("Starting Label section")
+	if model.IssueCommentActionDeleted == e.GetAction() || !e.GetIssue().IsPullRequest() {
+		return nil
 	}
 
 	commentBody := e.GetComment().GetBody()
@@ -46,20 +50,21 @@ func handleCommentLabel(c *Context, e *github.IssueCommentEvent) {
 	customLabelMatches := customLabelRegex.FindAllStringSubmatch(commentBody, -1)
 	customRemoveLabelMatches := customRemoveLabelRegex.FindAllStringSubmatch(commentBody, -1)
 	if len(labelMatches) == 0 && len(removeLabelMatches) == 0 && len(customLabelMatches) == 0 && len(customRemoveLabelMatches) == 0 {
-		return
+		return nil
 	}
 
 	org := e.GetRepo().GetOwner().GetLogin()
 	repo := e.GetRepo().GetName()
 	number := e.GetIssue().GetNumber()
+	additionalLabels := c.RepoConfig(org, repo).AdditionalLabels
 
 	repoLabels, err := c.GitHub.ListRepoLabels(org, repo)
 	if err != nil {
-		return
+		return errors.Wrapf(err, "failed to list repo labels on repo %s", repo)
 	}
 	labels, err := c.GitHub.GetIssueLabels(org, repo, number)
 	if err != nil {
-		return
+		return errors.Wrapf(err, "failed to list labels on PR #%d", number)
 	}
 
 	RepoLabelsExisting := sets.New[string]()
@@ -115,7 +120,7 @@ func handleCommentLabel(c *Context, e *github.IssueCommentEvent) {
 		if err != nil {
 			c.Logger.WithError(err).Error("Failed to create comment")
 		}
-		return
+		return nil
 	}
 
 	if len(noSuchLabelsInRepo) > 0 {
@@ -125,7 +130,7 @@ func handleCommentLabel(c *Context, e *github.IssueCommentEvent) {
 		if err != nil {
 			c.Logger.WithError(err).Error("Failed to create comment")
 		}
-		return
+		return nil
 	}
 
 	// Tried to remove Labels that were not present on the Issue
@@ -135,9 +140,10 @@ func handleCommentLabel(c *Context, e *github.IssueCommentEvent) {
 		if err != nil {
 			c.Logger.WithError(err).Error("Failed to create comment")
 		}
-		return
+		return nil
 	}
 
+	return nil
 }
 
 func buildGhLabel(name string, description string, color string) github.Label {
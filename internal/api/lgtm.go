@@ -0,0 +1,99 @@
+package api
+
+import (
+	"regexp"
+
+	"github.com/mattermost/chewbacca/internal/utils"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/pkg/errors"
+)
+
+const lgtmLabel = "lgtm"
+
+var (
+	lgtmRe       = regexp.MustCompile(`(?mi)^/lgtm\s*$`)
+	lgtmCancelRe = regexp.MustCompile(`(?mi)^/lgtm\s+cancel\s*$`)
+)
+
+// lgtmPlugin handles the /lgtm and /lgtm cancel commands, and removes the lgtm label
+// when new commits are pushed.
+type lgtmPlugin struct{}
+
+func (p *lgtmPlugin) Name() string {
+	return "lgtm"
+}
+
+func (p *lgtmPlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypePullRequest:  {model.PullRequestActionSynchronize},
+		model.EventTypeIssueComment: {model.IssueCommentActionCreated},
+	}
+}
+
+func (p *lgtmPlugin) Handle(c *Context, e *Event) error {
+	if e.PullRequest != nil {
+		return handleLGTMSynchronize(c, e)
+	}
+	if !e.IsPullRequest {
+		return nil
+	}
+	return handleLGTMComment(c, e)
+}
+
+func handleLGTMComment(c *Context, e *Event) error {
+	comment := e.IssueComment.GetComment()
+	body := comment.GetBody()
+
+	var wantLGTM bool
+	switch {
+	case lgtmCancelRe.MatchString(body):
+		wantLGTM = false
+	case lgtmRe.MatchString(body):
+		wantLGTM = true
+	default:
+		return nil
+	}
+
+	commenter := comment.GetUser().GetLogin()
+	isAuthor := utils.IsAuthor(e.IssueComment.GetIssue().GetUser().GetLogin(), commenter)
+	if wantLGTM && isAuthor {
+		c.Logger.Info("author tried to LGTM their own PR")
+		return c.GitHub.CreateComment(e.Org, e.Repo, e.Number, utils.FormatICResponse(comment, "you cannot LGTM your own PR."))
+	}
+
+	isMember, err := c.GitHub.IsMember(e.Org, commenter)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the membership")
+	}
+	if !isMember && !isAuthor {
+		c.Logger.Info("not member")
+		return c.GitHub.CreateComment(e.Org, e.Repo, e.Number, utils.FormatICResponse(comment, "only the org members can add or remove the `lgtm` label."))
+	}
+
+	hasLGTM := utils.HasLabel(lgtmLabel, e.IssueComment.GetIssue().Labels)
+	if wantLGTM && !hasLGTM {
+		c.Logger.Info("adding lgtm label")
+		return c.GitHub.AddLabels(e.Org, e.Repo, e.Number, []string{lgtmLabel})
+	}
+	if !wantLGTM && hasLGTM {
+		c.Logger.Info("removing lgtm label")
+		return c.GitHub.RemoveLabel(e.Org, e.Repo, e.Number, lgtmLabel)
+	}
+
+	return nil
+}
+
+func handleLGTMSynchronize(c *Context, e *Event) error {
+	if !utils.HasLabel(lgtmLabel, e.PullRequest.GetPullRequest().Labels) {
+		return nil
+	}
+
+	c.Logger.Info("removing lgtm label after new commits")
+	if err := c.GitHub.RemoveLabel(e.Org, e.Repo, e.Number, lgtmLabel); err != nil {
+		return err
+	}
+
+	msg := "New changes are detected. The `lgtm` label has been removed."
+	return c.GitHub.CreateComment(e.Org, e.Repo, e.Number, utils.FormatSimpleResponse(e.PullRequest.GetPullRequest().GetUser().GetLogin(), msg))
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mattermost/chewbacca/model"
+)
+
+func TestLGTMAndApprove(t *testing.T) {
+	testCases := []struct {
+		name       string
+		eventType  string
+		payload    []byte
+		wantLabels []string
+		wantCalls  []string
+	}{
+		{
+			name:       "lgtm by a member",
+			eventType:  model.EventTypeIssueComment,
+			payload:    testCommentPayload("bob", "alice", "/lgtm"),
+			wantLabels: []string{"lgtm"},
+			wantCalls:  []string{"AddLabels"},
+		},
+		{
+			name:      "lgtm by the author",
+			eventType: model.EventTypeIssueComment,
+			payload:   testCommentPayload("alice", "alice", "/lgtm"),
+			wantCalls: []string{"CreateComment"},
+		},
+		{
+			name:      "lgtm by a non member",
+			eventType: model.EventTypeIssueComment,
+			payload:   testCommentPayload("mallory", "alice", "/lgtm"),
+			wantCalls: []string{"CreateComment"},
+		},
+		{
+			name:      "lgtm cancel",
+			eventType: model.EventTypeIssueComment,
+			payload:   testCommentPayload("bob", "alice", "/lgtm cancel", "lgtm"),
+			wantCalls: []string{"RemoveLabel"},
+		},
+		{
+			name:      "lgtm cancel by the author",
+			eventType: model.EventTypeIssueComment,
+			payload:   testCommentPayload("alice", "alice", "/lgtm cancel", "lgtm"),
+			wantCalls: []string{"RemoveLabel"},
+		},
+		{
+			name:       "new commits drop lgtm",
+			eventType:  model.EventTypePullRequest,
+			payload:    testPullRequestPayload(model.PullRequestActionSynchronize, "alice", "lgtm", "approved"),
+			wantLabels: []string{"approved"},
+			wantCalls:  []string{"RemoveLabel", "CreateComment"},
+		},
+		{
+			name:       "approve by a member",
+			eventType:  model.EventTypeIssueComment,
+			payload:    testCommentPayload("bob", "alice", "/approve"),
+			wantLabels: []string{"approved"},
+			wantCalls:  []string{"AddLabels"},
+		},
+		{
+			name:       "approve by a non member",
+			eventType:  model.EventTypeIssueComment,
+			payload:    testCommentPayload("mallory", "alice", "/approve", "lgtm"),
+			wantLabels: []string{"lgtm"},
+			wantCalls:  []string{"CreateComment"},
+		},
+		{
+			name:       "approve cancel",
+			eventType:  model.EventTypeIssueComment,
+			payload:    testCommentPayload("bob", "alice", "/approve cancel", "lgtm", "approved"),
+			wantLabels: []string{"lgtm"},
+			wantCalls:  []string{"RemoveLabel"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, fake, recorder := newTestContext("lgtm, approve", "bob")
+			if err := processTestEvent(t, c, fake, tc.eventType, tc.payload); err != nil {
+				t.Fatal(err)
+			}
+
+			if labels := labelNames(fake); !reflect.DeepEqual(labels, tc.wantLabels) {
+				t.Errorf("expected the labels %v, got %v", tc.wantLabels, labels)
+			}
+			if calls := actionNames(recorder.Actions("org", "repo")); !reflect.DeepEqual(calls, tc.wantCalls) {
+				t.Errorf("expected the changes %v, got %v", tc.wantCalls, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/config"
+	"github.com/mattermost/chewbacca/internal/utils"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	lifecycleStaleLabel  = "lifecycle/stale"
+	lifecycleRottenLabel = "lifecycle/rotten"
+	lifecycleFrozenLabel = "lifecycle/frozen"
+
+	// LifecycleActionStale marks an issue as stale.
+	LifecycleActionStale = "stale"
+	// LifecycleActionRotten marks a stale issue as rotten.
+	LifecycleActionRotten = "rotten"
+	// LifecycleActionClose closes a rotten issue.
+	LifecycleActionClose = "close"
+)
+
+var (
+	lifecycleLabels = []string{lifecycleStaleLabel, lifecycleRottenLabel, lifecycleFrozenLabel}
+	lifecycleRe     = regexp.MustCompile(`(?mi)^/(remove-)?lifecycle\s+(frozen|stale|rotten)\s*$`)
+)
+
+// LifecycleAction is a lifecycle change of an inactive issue or pull request.
+type LifecycleAction struct {
+	Org           string        `json:"org"`
+	Repo          string        `json:"repo"`
+	Number        int           `json:"number"`
+	Title         string        `json:"title"`
+	IsPullRequest bool          `json:"is_pull_request"`
+	Action        string        `json:"action"`
+	Inactive      time.Duration `json:"inactive"`
+}
+
+// String describes the action in a report line.
+func (a *LifecycleAction) String() string {
+	kind := "issue"
+	if a.IsPullRequest {
+		kind = "PR"
+	}
+	return fmt.Sprintf("%s/%s#%d (%s, inactive for %d days) %s: %s", a.Org, a.Repo, a.Number, kind, int(a.Inactive.Hours()/24), a.Action, a.Title)
+}
+
+// lifecyclePlugin handles the /lifecycle and /remove-lifecycle commands.
+type lifecyclePlugin struct{}
+
+func (p *lifecyclePlugin) Name() string {
+	return "lifecycle"
+}
+
+func (p *lifecyclePlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypeIssueComment: {model.IssueCommentActionCreated},
+	}
+}
+
+func (p *lifecyclePlugin) Handle(c *Context, e *Event) error {
+	comment := e.IssueComment.GetComment()
+	matches := lifecycleRe.FindAllStringSubmatch(comment.GetBody(), -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	commenter := comment.GetUser().GetLogin()
+	if !utils.IsAuthor(e.IssueComment.GetIssue().GetUser().GetLogin(), commenter) {
+		isMember, err := c.GitHub.IsMember(e.Org, commenter)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the membership")
+		}
+		if !isMember {
+			c.Logger.Info("not member")
+			return c.GitHub.CreateComment(e.Org, e.Repo, e.Number, utils.FormatICResponse(comment, "only the org members or the author can change the lifecycle."))
+		}
+	}
+
+	currentLabels := utils.LabelsSet(e.IssueComment.GetIssue().Labels)
+	for _, match := range matches {
+		label := "lifecycle/" + strings.ToLower(match[2])
+		remove := match[1] != ""
+
+		if remove {
+			if !currentLabels.Has(label) {
+				continue
+			}
+			c.Logger.Infof("removing %s label", label)
+			if err := c.GitHub.RemoveLabel(e.Org, e.Repo, e.Number, label); err != nil {
+				return errors.Wrapf(err, "failed to remove the %s label", label)
+			}
+			currentLabels.Delete(label)
+			continue
+		}
+
+		err := removeOtherLabels(
+			func(l string) error {
+				return c.GitHub.RemoveLabel(e.Org, e.Repo, e.Number, l)
+			},
+			label,
+			lifecycleLabels,
+			currentLabels,
+		)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to remove the other lifecycle labels")
+		}
+		if currentLabels.Has(label) {
+			continue
+		}
+		c.Logger.Infof("adding %s label", label)
+		if err := c.GitHub.AddLabels(e.Org, e.Repo, e.Number, []string{label}); err != nil {
+			return errors.Wrapf(err, "failed to add the %s label", label)
+		}
+		currentLabels.Insert(label)
+	}
+
+	return nil
+}
+
+// RunLifecycle walks the inactive open issues and pull requests of the repository
+// through the stale, rotten and closed states, according to the inactivity windows
+// of the repository configuration. In dry-run mode the actions are only reported.
+func RunLifecycle(c *Context, org, repo string, dryRun bool, now time.Time) ([]*LifecycleAction, error) {
+	cfg := c.RepoConfig(org, repo).Lifecycle
+
+	issues, err := c.GitHub.ListOpenIssues(org, repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the open issues of %s/%s", org, repo)
+	}
+
+	var actions []*LifecycleAction
+	var errs []error
+	for _, issue := range issues {
+		action := lifecycleAction(cfg, issue, now)
+		if action == "" {
+			continue
+		}
+
+		a := &LifecycleAction{
+			Org:           org,
+			Repo:          repo,
+			Number:        issue.GetNumber(),
+			Title:         issue.GetTitle(),
+			IsPullRequest: issue.IsPullRequest(),
+			Action:        action,
+			Inactive:      now.Sub(issue.GetUpdatedAt()),
+		}
+		actions = append(actions, a)
+
+		logger := c.Logger.WithFields(log.Fields{
+			"number": a.Number,
+			"action": a.Action,
+		})
+		if dryRun {
+			logger.Info("lifecycle action skipped in dry-run mode")
+			continue
+		}
+
+		logger.Info("applying lifecycle action")
+		if err := applyLifecycleAction(c, cfg, issue, a); err != nil {
+			logger.WithError(err).Error("failed to apply the lifecycle action")
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return actions, errors.Errorf("failed to apply %d lifecycle actions in %s/%s", len(errs), org, repo)
+	}
+
+	return actions, nil
+}
+
+// lifecycleAction returns the lifecycle action due for the issue, if any. The
+// inactivity is measured from the last update, so each window starts when the
+// previous lifecycle label was added.
+func lifecycleAction(cfg config.LifecycleConfig, issue *github.Issue, now time.Time) string {
+	labels := utils.LabelsSet(issue.Labels)
+	if labels.Has(lifecycleFrozenLabel) {
+		return ""
+	}
+
+	inactive := now.Sub(issue.GetUpdatedAt())
+	switch {
+	case labels.Has(lifecycleRottenLabel):
+		if cfg.CloseAfterDays > 0 && inactive >= days(cfg.CloseAfterDays) {
+			return LifecycleActionClose
+		}
+	case labels.Has(lifecycleStaleLabel):
+		if cfg.RottenAfterDays > 0 && inactive >= days(cfg.RottenAfterDays) {
+			return LifecycleActionRotten
+		}
+	default:
+		if cfg.StaleAfterDays > 0 && inactive >= days(cfg.StaleAfterDays) {
+			return LifecycleActionStale
+		}
+	}
+
+	return ""
+}
+
+func applyLifecycleAction(c *Context, cfg config.LifecycleConfig, issue *github.Issue, a *LifecycleAction) error {
+	author := issue.GetUser().GetLogin()
+	inactiveDays := int(a.Inactive.Hours() / 24)
+
+	switch a.Action {
+	case LifecycleActionStale:
+		if err := c.GitHub.AddLabels(a.Org, a.Repo, a.Number, []string{lifecycleStaleLabel}); err != nil {
+			return err
+		}
+		return c.GitHub.CreateComment(a.Org, a.Repo, a.Number, utils.FormatSimpleResponse(author, fmt.Sprintf(
+			"this has had no activity for %d days and is now marked as stale. It will be marked as rotten after %d more days of inactivity, and then closed.\n\n"+
+				"Comment `/remove-lifecycle stale` to mark it as fresh, or `/lifecycle frozen` to exempt it from the lifecycle.",
+			inactiveDays, cfg.RottenAfterDays)))
+	case LifecycleActionRotten:
+		if err := c.GitHub.AddLabels(a.Org, a.Repo, a.Number, []string{lifecycleRottenLabel}); err != nil {
+			return err
+		}
+		if err := c.GitHub.RemoveLabel(a.Org, a.Repo, a.Number, lifecycleStaleLabel); err != nil {
+			return err
+		}
+		return c.GitHub.CreateComment(a.Org, a.Repo, a.Number, utils.FormatSimpleResponse(author, fmt.Sprintf(
+			"this stale item has had no activity for %d more days and is now marked as rotten. It will be closed after %d more days of inactivity.\n\n"+
+				"Comment `/remove-lifecycle rotten` to mark it as fresh, or `/lifecycle frozen` to exempt it from the lifecycle.",
+			inactiveDays, cfg.CloseAfterDays)))
+	case LifecycleActionClose:
+		if err := c.GitHub.CreateComment(a.Org, a.Repo, a.Number, utils.FormatSimpleResponse(author, fmt.Sprintf(
+			"this rotten item has had no activity for %d more days and is now closed.\n\n"+
+				"Reopen it and comment `/remove-lifecycle rotten` if it's still relevant.",
+			inactiveDays))); err != nil {
+			return err
+		}
+		return c.GitHub.CloseIssue(a.Org, a.Repo, a.Number)
+	}
+
+	return nil
+}
+
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
+
+// LifecycleJob periodically runs the lifecycle of the configured repositories.
+type LifecycleJob struct {
+	context *Context
+	repos   []string
+	dryRun  bool
+
+	*periodicJob
+}
+
+// NewLifecycleJob creates a job running the lifecycle of the repositories, given as
+// org/repo, at each interval.
+func NewLifecycleJob(c *Context, repos []string, interval time.Duration, dryRun bool) (*LifecycleJob, error) {
+	for _, repo := range repos {
+		if _, _, err := SplitRepo(repo); err != nil {
+			return nil, err
+		}
+	}
+
+	j := &LifecycleJob{
+		context: c,
+		repos:   repos,
+		dryRun:  dryRun,
+	}
+	j.periodicJob = newPeriodicJob(interval, j.run)
+	return j, nil
+}
+
+func (j *LifecycleJob) run() {
+	for _, fullName := range j.repos {
+		org, repo, _ := SplitRepo(fullName)
+
+		c := j.context.Clone()
+		c.RequestID = model.NewID()
+		c.Logger = c.Logger.WithFields(log.Fields{
+			"request": c.RequestID,
+			"org":     org,
+			"repo":    repo,
+			"dry_run": j.dryRun,
+		})
+		c.enableRepoDryRun(org, repo)
+
+		actions, err := RunLifecycle(c, org, repo, j.dryRun, time.Now())
+		if err != nil {
+			c.Logger.WithError(err).WithField("actions", len(actions)).Error("failed to run the lifecycle")
+			continue
+		}
+		c.Logger.WithField("actions", len(actions)).Info("lifecycle done")
+	}
+}
+
+// SplitRepo splits a repository full name in its organization and name.
+func SplitRepo(fullName string) (string, string, error) {
+	parts := strings.Split(fullName, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("invalid repository %q, expected org/repo", fullName)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/config"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/google/go-github/v31/github"
+)
+
+func TestLifecycleAction(t *testing.T) {
+	cfg := config.Default().Lifecycle
+	now := time.Now()
+
+	issue := func(inactiveDays int, labels ...string) *github.Issue {
+		updatedAt := now.Add(-days(inactiveDays))
+		i := &github.Issue{UpdatedAt: &updatedAt}
+		for _, label := range labels {
+			i.Labels = append(i.Labels, &github.Label{Name: github.String(label)})
+		}
+		return i
+	}
+
+	testCases := []struct {
+		name  string
+		issue *github.Issue
+		want  string
+	}{
+		{"active", issue(10), ""},
+		{"inactive", issue(90), LifecycleActionStale},
+		{"recently stale", issue(10, lifecycleStaleLabel), ""},
+		{"stale", issue(30, lifecycleStaleLabel), LifecycleActionRotten},
+		{"rotten", issue(30, lifecycleRottenLabel), LifecycleActionClose},
+		{"frozen", issue(400, lifecycleRottenLabel, lifecycleFrozenLabel), ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := lifecycleAction(cfg, tc.issue, now); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLifecycleCommand(t *testing.T) {
+	testCases := []struct {
+		commenter  string
+		wantLabels []string
+	}{
+		{"bob", []string{lifecycleFrozenLabel}},
+		{"alice", []string{lifecycleFrozenLabel}},
+		{"mallory", []string{lifecycleStaleLabel}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.commenter, func(t *testing.T) {
+			c, fake, _ := newTestContext("lifecycle", "bob")
+			payload := testCommentPayload(tc.commenter, "alice", "/lifecycle frozen", lifecycleStaleLabel)
+			if err := processTestEvent(t, c, fake, model.EventTypeIssueComment, payload); err != nil {
+				t.Fatal(err)
+			}
+			if labels := labelNames(fake); !reflect.DeepEqual(labels, tc.wantLabels) {
+				t.Fatalf("expected the labels %v, got %v", tc.wantLabels, labels)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/utils"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
+)
+
+const (
+	needsRebaseLabel = "needs-rebase"
+
+	// mergeablePollAttempts is the number of times a PR is fetched while GitHub
+	// computes whether it's mergeable.
+	mergeablePollAttempts = 5
+
+	// needsRebaseWorkers is the number of PRs checked concurrently after a push.
+	needsRebaseWorkers = 5
+)
+
+// mergeablePollInterval is the delay between two fetches of a PR whose mergeability
+// is not known yet.
+var mergeablePollInterval = 3 * time.Second
+
+// needsRebasePlugin labels the pull requests having conflicts with their base branch,
+// checking the open PRs each time their base branch moves.
+type needsRebasePlugin struct{}
+
+func (p *needsRebasePlugin) Name() string {
+	return "needs-rebase"
+}
+
+func (p *needsRebasePlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypePush: nil,
+		model.EventTypePullRequest: {
+			model.PullRequestActionOpened,
+			model.PullRequestActionReopened,
+			model.PullRequestActionSynchronize,
+		},
+	}
+}
+
+func (p *needsRebasePlugin) Handle(c *Context, e *Event) error {
+	if e.PullRequest != nil {
+		return checkNeedsRebase(c, e.Org, e.Repo, e.Number)
+	}
+
+	if e.Push.GetDeleted() || !strings.HasPrefix(e.Push.GetRef(), "refs/heads/") {
+		return nil
+	}
+	branch := strings.TrimPrefix(e.Push.GetRef(), "refs/heads/")
+
+	prs, err := c.GitHub.ListPullRequests(e.Org, e.Repo, branch)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the PRs targeting %s", branch)
+	}
+
+	numbers := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var failed int
+	for i := 0; i < needsRebaseWorkers && i < len(prs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for number := range numbers {
+				if err := checkNeedsRebase(c, e.Org, e.Repo, number); err != nil {
+					c.Logger.WithError(err).Errorf("failed to check if PR#%d needs a rebase", number)
+					mu.Lock()
+					failed++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, pr := range prs {
+		numbers <- pr.GetNumber()
+	}
+	close(numbers)
+	wg.Wait()
+
+	if failed > 0 {
+		return errors.Errorf("failed to check %d of the %d PRs targeting %s", failed, len(prs), branch)
+	}
+
+	return nil
+}
+
+// checkNeedsRebase adds the needs-rebase label to the PR when it has conflicts, and
+// removes it once the PR is mergeable again. The PR is skipped when GitHub didn't
+// compute its mergeability in time, it is checked again on the next push.
+func checkNeedsRebase(c *Context, org, repo string, number int) error {
+	pr, err := getMergeablePullRequest(c, org, repo, number)
+	if err != nil {
+		return err
+	}
+	if pr.GetState() == "closed" {
+		return nil
+	}
+	if pr.Mergeable == nil {
+		c.Logger.Infof("skipping PR#%d, its mergeability is still unknown", number)
+		return nil
+	}
+
+	hasLabel := utils.HasLabel(needsRebaseLabel, pr.Labels)
+	if pr.GetMergeable() {
+		if !hasLabel {
+			return nil
+		}
+		c.Logger.Infof("removing %s label from PR#%d", needsRebaseLabel, number)
+		return c.GitHub.RemoveLabel(org, repo, number, needsRebaseLabel)
+	}
+	if hasLabel {
+		return nil
+	}
+
+	c.Logger.Infof("adding %s label to PR#%d", needsRebaseLabel, number)
+	if err := c.GitHub.AddLabels(org, repo, number, []string{needsRebaseLabel}); err != nil {
+		return errors.Wrapf(err, "failed to add the %s label", needsRebaseLabel)
+	}
+
+	return c.GitHub.CreateComment(org, repo, number, utils.FormatSimpleResponse(pr.GetUser().GetLogin(), "the PR has conflicts with its base branch and can't be merged. Please rebase it, the `needs-rebase` label is removed once the conflicts are resolved."))
+}
+
+// getMergeablePullRequest fetches the PR until GitHub finished computing whether it's
+// mergeable, which is done in the background after the PR or its base branch changed.
+// The mergeability of the returned PR is still unknown after too many attempts.
+func getMergeablePullRequest(c *Context, org, repo string, number int) (*github.PullRequest, error) {
+	for attempt := 1; ; attempt++ {
+		pr, err := c.GitHub.GetPullRequest(org, repo, number)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the PR#%d", number)
+		}
+		if pr.Mergeable != nil || pr.GetState() == "closed" {
+			return pr, nil
+		}
+		if attempt == mergeablePollAttempts {
+			return pr, nil
+		}
+		time.Sleep(mergeablePollInterval)
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mattermost/chewbacca/model"
+)
+
+func TestNeedsRebase(t *testing.T) {
+	defer func(interval time.Duration) { mergeablePollInterval = interval }(mergeablePollInterval)
+	mergeablePollInterval = time.Millisecond
+
+	c, fake, recorder := newTestContext("needs-rebase")
+	observePR := func(number int, base, mergeable string, labels ...string) {
+		fake.Observe(model.EventTypePullRequest, []byte(fmt.Sprintf(`{
+			"action": "opened",
+			"number": %d,
+			"pull_request": {"number": %d, "state": "open", "mergeable": %s, "user": {"login": "alice"}, "labels": %s, "base": {"ref": %q}},
+			"repository": {"name": "repo", "owner": {"login": "org"}}
+		}`, number, number, mergeable, testLabels(labels), base)))
+	}
+	observePR(1, "master", "false")
+	observePR(2, "master", "true", needsRebaseLabel)
+	observePR(3, "master", "null")
+	observePR(4, "master", "false", needsRebaseLabel)
+	observePR(5, "release-9.1", "false")
+
+	payload := []byte(`{"ref": "refs/heads/master", "repository": {"name": "repo", "owner": {"login": "org"}}}`)
+	if err := processTestEvent(t, c, fake, model.EventTypePush, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := make(map[int][]string)
+	for _, action := range recorder.Actions("org", "repo") {
+		changes[action.Number] = append(changes[action.Number], action.Action)
+	}
+	expected := map[int][]string{
+		1: {"AddLabels", "CreateComment"},
+		2: {"RemoveLabel"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected the changes %v, got %v", expected, changes)
+	}
+
+	for number, want := range map[int]bool{1: true, 2: false, 3: false, 4: true, 5: false} {
+		labels, _ := fake.GetIssueLabels("org", "repo", number)
+		if has := len(labels) == 1 && labels[0].GetName() == needsRebaseLabel; has != want {
+			t.Errorf("expected the label on PR#%d to be %t, got %v", number, want, labels)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/owners"
+	"github.com/mattermost/chewbacca/internal/utils"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ownersPlugin requests reviews from the OWNERS of the changed files and applies the
+// labels of their OWNERS files when a PR is opened.
+type ownersPlugin struct{}
+
+func (p *ownersPlugin) Name() string {
+	return "owners"
+}
+
+func (p *ownersPlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypePullRequest: {model.PullRequestActionOpened},
+	}
+}
+
+func (p *ownersPlugin) Handle(c *Context, e *Event) error {
+	pr := e.PullRequest.GetPullRequest()
+
+	repoOwners, files, err := loadOwners(c, e.Org, e.Repo, pr)
+	if err != nil {
+		return err
+	}
+	if repoOwners.Empty() {
+		return nil
+	}
+
+	labels := sets.New[string]()
+	for _, file := range files {
+		labels = labels.Union(repoOwners.Labels(file))
+	}
+	for _, label := range pr.Labels {
+		labels.Delete(label.GetName())
+	}
+	if labels.Len() > 0 {
+		c.Logger.Infof("adding the OWNERS labels %v", sets.List(labels))
+		if err := c.GitHub.AddLabels(e.Org, e.Repo, e.Number, sets.List(labels)); err != nil {
+			return errors.Wrap(err, "failed to add the OWNERS labels")
+		}
+	}
+
+	count := c.RepoConfig(e.Org, e.Repo).RequestedReviewers
+	if count <= 0 {
+		return nil
+	}
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	reviewers := repoOwners.SelectReviewers(files, pr.GetUser().GetLogin(), count, rnd)
+	if len(reviewers) == 0 {
+		return nil
+	}
+
+	c.Logger.Infof("requesting the review of %v", reviewers)
+	if err := c.GitHub.RequestReviewers(e.Org, e.Repo, e.Number, reviewers); err != nil {
+		return errors.Wrap(err, "failed to request the reviewers")
+	}
+
+	return nil
+}
+
+// ownersApproval is the approval state of a PR whose files are owned by OWNERS files.
+type ownersApproval struct {
+	Owners *owners.Owners
+	// Files are the files changed by the PR.
+	Files []string
+	// Approvers are the approvers who approved the PR.
+	Approvers sets.Set[string]
+	// Unapproved are the files not approved yet.
+	Unapproved []string
+}
+
+// Approved returns whether every changed file is approved.
+func (a *ownersApproval) Approved() bool {
+	return len(a.Unapproved) == 0
+}
+
+// loadOwners returns the OWNERS files of the files changed by the PR, read from its
+// base branch so a PR can't change its own approvers, and the changed files.
+func loadOwners(c *Context, org, repo string, pr *github.PullRequest) (*owners.Owners, []string, error) {
+	commitFiles, err := c.GitHub.ListPullRequestFiles(org, repo, pr.GetNumber())
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to list the files of PR#%d", pr.GetNumber())
+	}
+
+	var files []string
+	for _, file := range commitFiles {
+		files = append(files, file.GetFilename())
+		// The old path of a renamed file is changed too.
+		if file.GetPreviousFilename() != "" {
+			files = append(files, file.GetPreviousFilename())
+		}
+	}
+
+	repoOwners, err := owners.Load(c.GitHub, org, repo, pr.GetBase().GetSHA(), files)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to load the OWNERS files")
+	}
+
+	return repoOwners, files, nil
+}
+
+// getOwnersApproval returns the approval state of the PR, computed from the /approve
+// commands of the approvers. It returns nil when the changed files have no OWNERS.
+// The current comment is taken into account even if it's not listed yet.
+func getOwnersApproval(c *Context, org, repo string, pr *github.PullRequest, current *github.IssueComment) (*ownersApproval, error) {
+	repoOwners, files, err := loadOwners(c, org, repo, pr)
+	if err != nil {
+		return nil, err
+	}
+	if repoOwners.Empty() {
+		return nil, nil
+	}
+
+	comments, err := c.GitHub.ListIssueComments(org, repo, pr.GetNumber())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the comments of PR#%d", pr.GetNumber())
+	}
+	if current != nil {
+		listed := false
+		for _, comment := range comments {
+			if comment.GetID() == current.GetID() {
+				listed = true
+				break
+			}
+		}
+		if !listed {
+			comments = append(comments, current)
+		}
+	}
+
+	allApprovers := sets.New[string]()
+	for _, file := range files {
+		allApprovers = allApprovers.Union(repoOwners.Approvers(file))
+	}
+
+	approvers := sets.New[string]()
+	for _, comment := range comments {
+		login := utils.NormLogin(comment.GetUser().GetLogin())
+		if !allApprovers.Has(login) {
+			continue
+		}
+		switch {
+		case approveCancelRe.MatchString(comment.GetBody()):
+			approvers.Delete(login)
+		case approveRe.MatchString(comment.GetBody()):
+			approvers.Insert(login)
+		}
+	}
+
+	return &ownersApproval{
+		Owners:     repoOwners,
+		Files:      files,
+		Approvers:  approvers,
+		Unapproved: repoOwners.Unapproved(files, approvers),
+	}, nil
+}
+
+// ownersApprovalReason returns the reason blocking the merge of a PR which is not
+// approved yet, with an annotation on the OWNERS file of each unapproved file.
+func ownersApprovalReason(approval *ownersApproval) blockReason {
+	byOwnersFile := make(map[string][]string)
+	for _, file := range approval.Unapproved {
+		ownersFile := approval.Owners.OwnersFile(file)
+		byOwnersFile[ownersFile] = append(byOwnersFile[ownersFile], file)
+	}
+
+	var ownersFiles []string
+	for ownersFile := range byOwnersFile {
+		ownersFiles = append(ownersFiles, ownersFile)
+	}
+	sort.Strings(ownersFiles)
+
+	reason := blockReason{
+		Rule:    "approval",
+		Message: fmt.Sprintf("%d of the changed files need the approval of their OWNERS. An approver of each file should comment `/approve`.", len(approval.Unapproved)),
+	}
+	for _, ownersFile := range ownersFiles {
+		files := byOwnersFile[ownersFile]
+		reason.Annotations = append(reason.Annotations, &github.CheckRunAnnotation{
+			Path:            github.String(ownersFile),
+			StartLine:       github.Int(1),
+			EndLine:         github.Int(1),
+			AnnotationLevel: github.String("notice"),
+			Title:           github.String("Approval needed"),
+			Message:         github.String(fmt.Sprintf("Needs the approval of %s for:\n%s", formatLogins(sets.List(approval.Owners.Approvers(files[0]))), strings.Join(files, "\n"))),
+		})
+	}
+
+	return reason
+}
+
+// formatLogins formats the logins as GitHub mentions.
+func formatLogins(logins []string) string {
+	mentions := make([]string, 0, len(logins))
+	for _, login := range logins {
+		mentions = append(mentions, "@"+login)
+	}
+	return strings.Join(mentions, ", ")
+}
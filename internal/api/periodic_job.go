@@ -0,0 +1,50 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// periodicJob runs a function at a fixed interval in the background.
+type periodicJob struct {
+	interval time.Duration
+	fn       func()
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newPeriodicJob(interval time.Duration, fn func()) *periodicJob {
+	return &periodicJob{
+		interval: interval,
+		fn:       fn,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start runs the job now and then at each interval, until the job is stopped.
+func (j *periodicJob) Start() {
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			j.fn()
+
+			select {
+			case <-j.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the job, waiting for the current run to finish.
+func (j *periodicJob) Stop() {
+	close(j.stop)
+	j.wg.Wait()
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/metrics"
+
+	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Event is a webhook event dispatched to the plugins.
+type Event struct {
+	Type   string
+	Action string
+	Org    string
+	Repo   string
+	// Number is the number of the issue or pull request the event is about, if any.
+	Number int
+	// IsPullRequest is set when the event is about a pull request.
+	IsPullRequest bool
+
+	PullRequest  *github.PullRequestEvent
+	IssueComment *github.IssueCommentEvent
+	CheckRun     *github.CheckRunEvent
+	Push         *github.PushEvent
+}
+
+// Plugin describes a self-contained behaviour of the bot reacting to webhook events.
+type Plugin interface {
+	// Name returns the name used to enable the plugin in the repository configuration.
+	Name() string
+	// Events returns the handled event types mapped to the handled actions. No
+	// actions means every action of the event type is handled.
+	Events() map[string][]string
+	// Handle reacts to an event.
+	Handle(c *Context, e *Event) error
+}
+
+// Registry dispatches the events to the registered plugins, in registration order.
+type Registry struct {
+	plugins []Plugin
+}
+
+// NewRegistry creates a registry with the given plugins.
+func NewRegistry(plugins ...Plugin) *Registry {
+	return &Registry{plugins: plugins}
+}
+
+// NewDefaultRegistry creates a registry with all the built-in plugins.
+func NewDefaultRegistry() *Registry {
+	return NewRegistry(
+		&releaseNotesPlugin{},
+		&labelPlugin{},
+		&lgtmPlugin{},
+		&approvePlugin{},
+		&ownersPlugin{},
+		&holdPlugin{},
+		&sizePlugin{},
+		&areaPlugin{},
+		&needsRebasePlugin{},
+		&lifecyclePlugin{},
+		&welcomePlugin{},
+		&conventionalTitlePlugin{},
+		&cherryPickPlugin{},
+		// The blocker runs last to see the labels set by the other plugins.
+		&blockerPlugin{},
+	)
+}
+
+// Dispatch runs the plugins handling the event which are enabled for the repository.
+// When only is not empty, the plugins not in it are skipped. Each plugin runs in
+// isolation and the names of the plugins which failed are returned.
+func (r *Registry) Dispatch(c *Context, e *Event, only []string) []string {
+	enabled := sets.New[string](c.RepoConfig(e.Org, e.Repo).Plugins...)
+	onlySet := sets.New[string](only...)
+
+	var failed []string
+	for _, plugin := range r.plugins {
+		if !enabled.Has(plugin.Name()) || (len(only) > 0 && !onlySet.Has(plugin.Name())) {
+			continue
+		}
+		if !handles(plugin, e) {
+			continue
+		}
+
+		logger := c.Logger.WithField("plugin", plugin.Name())
+		start := time.Now()
+		err := runPlugin(c, plugin, e)
+		metrics.PluginDuration.WithLabelValues(plugin.Name()).Observe(time.Since(start).Seconds())
+		if err != nil {
+			logger.WithError(err).Error("plugin failed")
+			metrics.PluginErrors.WithLabelValues(plugin.Name()).Inc()
+			failed = append(failed, plugin.Name())
+			continue
+		}
+		logger.WithField("duration", time.Since(start)).Debug("plugin done")
+	}
+
+	return failed
+}
+
+func handles(plugin Plugin, e *Event) bool {
+	actions, ok := plugin.Events()[e.Type]
+	if !ok {
+		return false
+	}
+	if len(actions) == 0 {
+		return true
+	}
+	for _, action := range actions {
+		if action == e.Action {
+			return true
+		}
+	}
+	return false
+}
+
+// runPlugin runs a plugin with its own logger, turning a panic into an error so it
+// doesn't affect the other plugins.
+func runPlugin(c *Context, plugin Plugin, e *Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic in plugin %s: %v", plugin.Name(), r)
+		}
+	}()
+
+	pc := c.Clone()
+	pc.RequestID = c.RequestID
+	pc.Logger = c.Logger.WithField("plugin", plugin.Name())
+
+	return plugin.Handle(pc, e)
+}
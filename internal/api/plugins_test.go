@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/config"
+	"github.com/mattermost/chewbacca/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type fakeFileGetter map[string]string
+
+func (f fakeFileGetter) GetFileContent(org, repo, path, ref string) ([]byte, error) {
+	return []byte(f[org+"/"+repo+"/"+path]), nil
+}
+
+type fakePlugin struct {
+	name    string
+	events  map[string][]string
+	handler func() error
+	calls   int
+}
+
+func (p *fakePlugin) Name() string                { return p.name }
+func (p *fakePlugin) Events() map[string][]string { return p.events }
+func (p *fakePlugin) Handle(c *Context, e *Event) error {
+	p.calls++
+	return p.handler()
+}
+
+func TestRegistryDispatch(t *testing.T) {
+	logger := log.New()
+	c := &Context{
+		Config: config.NewStore(fakeFileGetter{
+			"org/repo/.chewbacca.yaml": "plugins: [failing, panicking, working]",
+		}, time.Minute, logger),
+		Logger: logger,
+	}
+
+	prEvents := map[string][]string{model.EventTypePullRequest: {model.PullRequestActionOpened}}
+	failing := &fakePlugin{name: "failing", events: prEvents, handler: func() error { return errors.New("failure") }}
+	panicking := &fakePlugin{name: "panicking", events: prEvents, handler: func() error { panic("boom") }}
+	working := &fakePlugin{name: "working", events: prEvents, handler: func() error { return nil }}
+	disabled := &fakePlugin{name: "disabled", events: prEvents, handler: func() error { return nil }}
+	registry := NewRegistry(failing, panicking, working, disabled)
+
+	e := &Event{Type: model.EventTypePullRequest, Action: model.PullRequestActionOpened, Org: "org", Repo: "repo"}
+	failed := registry.Dispatch(c, e, nil)
+	if len(failed) != 2 || failed[0] != "failing" || failed[1] != "panicking" {
+		t.Fatalf("expected the failing and panicking plugins to fail, got %v", failed)
+	}
+	if working.calls != 1 || disabled.calls != 0 {
+		t.Fatalf("expected only the enabled plugins to run, got working=%d disabled=%d", working.calls, disabled.calls)
+	}
+
+	failed = registry.Dispatch(c, e, []string{"failing"})
+	if len(failed) != 1 || failing.calls != 2 || panicking.calls != 1 || working.calls != 1 {
+		t.Fatalf("expected only the failing plugin to be retried, got %v", failed)
+	}
+
+	e.Action = model.PullRequestActionClosed
+	if failed = registry.Dispatch(c, e, nil); len(failed) != 0 || working.calls != 1 {
+		t.Fatal("expected no plugin to handle the closed action")
+	}
+}
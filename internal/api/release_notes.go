@@ -9,6 +9,7 @@ import (
 	"github.com/mattermost/chewbacca/model"
 
 	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -21,14 +22,13 @@ const (
 	releaseNoteActionRequired = "release-note-action-required"
 	deprecationLabel          = "kind/deprecation"
 
-	releaseNoteFormat            = `Adding the "%s" label because no release-note block was detected, please follow our [release note process](https://github.com/mattermost/chewbacca#release-notes-process) to remove it.`
+	releaseNoteFormat            = `Adding the "%s" label because no release-note block was detected, please follow our [release note process](%s) to remove it.`
 	releaseNoteDeprecationFormat = `Adding the "%s" label and removing any existing "%s" label because there is a "%s" label on the PR.`
 
 	actionRequiredNote = "action required"
 )
 
 var (
-	releaseNoteBody            = fmt.Sprintf(releaseNoteFormat, ReleaseNoteLabelNeeded)
 	releaseNoteDeprecationBody = fmt.Sprintf(releaseNoteDeprecationFormat, ReleaseNoteLabelNeeded, releaseNoteNone, deprecationLabel)
 
 	noteMatcherRE = regexp.MustCompile(`(?s)(?:Release note\*\*:\s*(?:<!--[^<>]*-->\s*)?` + "```(?:release-note)?|```release-note)(.+?)```")
@@ -44,17 +44,44 @@ var (
 	releaseNoteNoneRe = regexp.MustCompile(`(?mi)^/release-note-none\s*$`)
 )
 
-func handleReleaseNotesPR(c *Context, pr *github.PullRequestEvent) {
+// releaseNotesPlugin sets the release-note labels from the release-note block of the
+// PR body and the /release-note-none command.
+type releaseNotesPlugin struct{}
+
+func (p *releaseNotesPlugin) Name() string {
+	return "release-notes"
+}
+
+func (p *releaseNotesPlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypePullRequest: {
+			model.PullRequestActionOpened,
+			model.PullRequestActionReopened,
+			model.PullRequestActionEdited,
+			model.PullRequestActionLabeled,
+		},
+		model.EventTypeIssueComment: {model.IssueCommentActionCreated},
+	}
+}
+
+func (p *releaseNotesPlugin) Handle(c *Context, e *Event) error {
+	if e.PullRequest != nil {
+		return handleReleaseNotesPR(c, e.PullRequest)
+	}
+	return handleReleaseNotesComment(c, e.IssueComment)
+}
+
+func handleReleaseNotesPR(c *Context, pr *github.PullRequestEvent) error {
 	// Only consider events that edit the PR body or add a label
 	if pr.GetAction() != model.PullRequestActionOpened &&
 		pr.GetAction() != model.PullRequestActionReopened &&
 		pr.GetAction() != model.PullRequestActionEdited &&
 		pr.GetAction() != model.PullRequestActionLabeled {
-		return
+		return nil
 	}
 
 	if pr.GetPullRequest().GetState() == "closed" {
-		return
+		return nil
 	}
 
 	org := pr.GetRepo().GetOwner().GetLogin()
@@ -64,65 +91,28 @@ func handleReleaseNotesPR(c *Context, pr *github.PullRequestEvent) {
 	branchName := pr.GetPullRequest().GetHead().GetRef()
 	repoLabels, err := c.GitHub.ListRepoLabels(org, repo)
 	if err != nil {
-		c.Logger.WithError(err).Errorf("failed to list repo labels on repo #%s", repo)
-		return
+		return errors.Wrapf(err, "failed to list repo labels on repo %s", repo)
 	}
 
 	repolabelsexisting := sets.New[string]()
 	for _, l := range repoLabels {
 		repolabelsexisting.Insert(strings.ToLower(l.GetName()))
 	}
-	branchList := []string{
-		"feat/",
-		"fix/",
-		"test/",
-		"chore/",
-		"refactor/",
-	}
-
-	branchToLabel := map[string]string{
-		"feat/":     "kind/feature",
-		"docs/":     "kind/documentation",
-		"fix/":      "kind/bug",
-		"test/":     "kind/testing",
-		"chore/":    "kind/chore",
-		"refactor/": "kind/refactor",
-	}
-	labelsToColours := map[string]string{
-		"kind/feature":       "c7def8",
-		"kind/documentation": "c7def8",
-		"kind/bug":           "e11d21",
-		"kind/chore":         "c7def8",
-		"kind/refactor":      "c7def8",
-		"kind/testing":       "79D6D6",
-	}
-	labelsToDescriptions := map[string]string{
-		"kind/feature":       "Categorizes issue or PR as related to a new feature.",
-		"kind/documentation": "Categorizes issue or PR as related to documentation.",
-		"kind/bug":           "Categorizes issue or PR as related to a bug.",
-		"kind/chore":         "Categorizes issue or PR as related to updates that are not production code.",
-		"kind/refactor":      "Categorizes issue or PR as related to refactor of production code.",
-		"kind/testing":       "Categorizes issue or PR as related to addition or refactoring of tests.",
-	}
-
-	var branchLabels []string
-	for _, conventionSubstring := range branchList {
-		if strings.Contains(branchName, conventionSubstring) {
-			branchLabels = append(branchLabels, branchToLabel[conventionSubstring])
-			continue
-		}
-	}
+	branchLabels := c.RepoConfig(org, repo).BranchLabels(branchName)
 	if len(branchLabels) > 0 {
-		if !repolabelsexisting.Has(branchLabels[0]) {
-			err = c.GitHub.CreateLabel(org, repo, buildGhLabel(branchLabels[0], labelsToDescriptions[branchLabels[0]], labelsToColours[branchLabels[0]]))
+		if !repolabelsexisting.Has(strings.ToLower(branchLabels[0].Label)) {
+			err = c.GitHub.CreateLabel(org, repo, buildGhLabel(branchLabels[0].Label, branchLabels[0].Description, branchLabels[0].Color))
 			if err != nil {
 				c.Logger.WithError(err).Error("Failed to create label")
 			}
 		}
-		err = c.GitHub.AddLabels(org, repo, number, branchLabels)
+		var labels []string
+		for _, branchLabel := range branchLabels {
+			labels = append(labels, branchLabel.Label)
+		}
+		err = c.GitHub.AddLabels(org, repo, number, labels)
 		if err != nil {
-			c.Logger.WithError(err).Errorf("failed to add branch labels on PR #%d", number)
-			return
+			return errors.Wrapf(err, "failed to add branch labels on PR #%d", number)
 		}
 	}
 
@@ -147,13 +137,12 @@ func handleReleaseNotesPR(c *Context, pr *github.PullRequestEvent) {
 		} else {
 			comments, err = c.GitHub.ListIssueComments(org, repo, number)
 			if err != nil {
-				c.Logger.WithError(err).Errorf("failed to list comments on %s/%s#%d.", org, repo, number)
-				return
+				return errors.Wrapf(err, "failed to list comments on %s/%s#%d", org, repo, number)
 			}
 			if containsNoneCommand(comments) {
 				labelToAdd = releaseNoteNone
 			} else if !prLabels.Has(ReleaseNoteLabelNeeded) {
-				comment := utils.FormatSimpleResponse(user, releaseNoteBody)
+				comment := utils.FormatSimpleResponse(user, fmt.Sprintf(releaseNoteFormat, ReleaseNoteLabelNeeded, utils.ReleaseNoteProcessURL()))
 				c.GitHub.CreateComment(org, repo, number, comment)
 			}
 		}
@@ -165,7 +154,7 @@ func handleReleaseNotesPR(c *Context, pr *github.PullRequestEvent) {
 		prLabels.Insert(labelToAdd)
 	}
 
-	err = removeOtherLabels(
+	return removeOtherLabels(
 		func(l string) error {
 			return c.GitHub.RemoveLabel(org, repo, number, l)
 		},
@@ -173,9 +162,6 @@ func handleReleaseNotesPR(c *Context, pr *github.PullRequestEvent) {
 		allRNLabels,
 		prLabels,
 	)
-	if err != nil {
-		c.Logger.WithError(err)
-	}
 }
 
 func handleReleaseNotesComment(c *Context, ic *github.IssueCommentEvent) error {
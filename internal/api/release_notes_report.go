@@ -0,0 +1,235 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/chewbacca/internal/utils"
+
+	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
+)
+
+const (
+	actionRequiredSection = "Action Required"
+	uncategorizedSection  = "Uncategorized"
+)
+
+// kindSections are the titles of the release notes sections of the kind labels, in
+// the order of the sections.
+var kindSections = []struct {
+	Label string
+	Title string
+}{
+	{"kind/api-change", "API Changes"},
+	{"kind/feature", "Features"},
+	{"kind/bug", "Bug Fixes"},
+	{"kind/regression", "Regressions"},
+	{"kind/deprecation", "Deprecations"},
+	{"kind/design", "Design"},
+	{"kind/documentation", "Documentation"},
+	{"kind/cleanup", "Cleanups"},
+}
+
+// ReleaseNote is the release note of a merged pull request.
+type ReleaseNote struct {
+	Number         int      `json:"number"`
+	Title          string   `json:"title"`
+	Author         string   `json:"author"`
+	URL            string   `json:"url"`
+	Note           string   `json:"note"`
+	Kinds          []string `json:"kinds"`
+	ActionRequired bool     `json:"action_required"`
+}
+
+// ReleaseNotesSection groups the release notes of a kind.
+type ReleaseNotesSection struct {
+	Title string         `json:"title"`
+	Notes []*ReleaseNote `json:"notes"`
+}
+
+// ReleaseNotes are the release notes of the pull requests merged between two refs.
+type ReleaseNotes struct {
+	Org      string                 `json:"org"`
+	Repo     string                 `json:"repo"`
+	From     string                 `json:"from"`
+	To       string                 `json:"to"`
+	Sections []*ReleaseNotesSection `json:"sections"`
+}
+
+// GenerateReleaseNotes collects the release notes of the pull requests merged between
+// the from and to refs, grouped by kind. The notes requiring an action from the users
+// have their own section.
+func GenerateReleaseNotes(c *Context, org, repo, from, to string) (*ReleaseNotes, error) {
+	commits, err := c.GitHub.CompareCommits(org, repo, from, to)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compare %s and %s", from, to)
+	}
+
+	var notes []*ReleaseNote
+	seen := make(map[int]bool)
+	for _, commit := range commits {
+		prs, err := c.GitHub.ListPullRequestsWithCommit(org, repo, commit.GetSHA())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the PRs of the commit %s", commit.GetSHA())
+		}
+
+		for _, pr := range prs {
+			if pr.MergedAt == nil || seen[pr.GetNumber()] {
+				continue
+			}
+			seen[pr.GetNumber()] = true
+
+			if note := releaseNoteOf(pr); note != nil {
+				notes = append(notes, note)
+			} else {
+				c.Logger.WithField("pr", pr.GetNumber()).Debug("no release note")
+			}
+		}
+	}
+
+	return &ReleaseNotes{
+		Org:      org,
+		Repo:     repo,
+		From:     from,
+		To:       to,
+		Sections: groupReleaseNotes(notes),
+	}, nil
+}
+
+// releaseNoteOf returns the release note of the PR, or nil if the PR doesn't have one.
+func releaseNoteOf(pr *github.PullRequest) *ReleaseNote {
+	labels := utils.LabelsSet(pr.Labels)
+	if labels.Has(releaseNoteNone) {
+		return nil
+	}
+
+	switch determineReleaseNoteLabel(pr.GetBody(), labels) {
+	case releaseNoteNone, ReleaseNoteLabelNeeded:
+		return nil
+	case releaseNoteActionRequired:
+		labels.Insert(releaseNoteActionRequired)
+	}
+
+	var kinds []string
+	for _, label := range pr.Labels {
+		if strings.HasPrefix(label.GetName(), "kind/") {
+			kinds = append(kinds, label.GetName())
+		}
+	}
+	sort.Strings(kinds)
+
+	return &ReleaseNote{
+		Number:         pr.GetNumber(),
+		Title:          pr.GetTitle(),
+		Author:         pr.GetUser().GetLogin(),
+		URL:            pr.GetHTMLURL(),
+		Note:           getReleaseNote(pr.GetBody()),
+		Kinds:          kinds,
+		ActionRequired: labels.Has(releaseNoteActionRequired),
+	}
+}
+
+// groupReleaseNotes groups the notes in sections: the notes requiring an action
+// first, then the known kinds, the other kinds, and the notes without kind last.
+// A note with several kinds is in the section of its first kind.
+func groupReleaseNotes(notes []*ReleaseNote) []*ReleaseNotesSection {
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].Number < notes[j].Number
+	})
+
+	titles := make(map[string]string)
+	order := make(map[string]int)
+	for i, kind := range kindSections {
+		titles[kind.Label] = kind.Title
+		order[kind.Label] = i
+	}
+
+	sections := make(map[string]*ReleaseNotesSection)
+	var keys []string
+	add := func(key, title string, note *ReleaseNote) {
+		section, ok := sections[key]
+		if !ok {
+			section = &ReleaseNotesSection{Title: title}
+			sections[key] = section
+			keys = append(keys, key)
+		}
+		section.Notes = append(section.Notes, note)
+	}
+
+	for _, note := range notes {
+		switch {
+		case note.ActionRequired:
+			add(actionRequiredSection, actionRequiredSection, note)
+		case len(note.Kinds) == 0:
+			add(uncategorizedSection, uncategorizedSection, note)
+		default:
+			kind := note.Kinds[0]
+			for _, k := range note.Kinds {
+				if _, known := order[k]; known {
+					kind = k
+					break
+				}
+			}
+			title, ok := titles[kind]
+			if !ok {
+				title = strings.TrimPrefix(kind, "kind/")
+			}
+			add(kind, title, note)
+		}
+	}
+
+	rank := func(key string) (int, string) {
+		switch key {
+		case actionRequiredSection:
+			return -1, ""
+		case uncategorizedSection:
+			return len(kindSections) + 1, ""
+		}
+		if i, ok := order[key]; ok {
+			return i, ""
+		}
+		return len(kindSections), key
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, ni := rank(keys[i])
+		rj, nj := rank(keys[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return ni < nj
+	})
+
+	result := make([]*ReleaseNotesSection, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, sections[key])
+	}
+	return result
+}
+
+// Markdown formats the release notes in Markdown.
+func (r *ReleaseNotes) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Release notes of %s/%s from %s to %s\n", r.Org, r.Repo, r.From, r.To)
+
+	if len(r.Sections) == 0 {
+		b.WriteString("\nNo release notes.\n")
+		return b.String()
+	}
+
+	for _, section := range r.Sections {
+		fmt.Fprintf(&b, "\n## %s\n\n", section.Title)
+		for _, note := range section.Notes {
+			lines := strings.Split(note.Note, "\n")
+			fmt.Fprintf(&b, "- %s ([#%d](%s), @%s)\n", strings.TrimSpace(lines[0]), note.Number, note.URL, note.Author)
+			for _, line := range lines[1:] {
+				if line = strings.TrimSpace(line); line != "" {
+					fmt.Fprintf(&b, "  %s\n", line)
+				}
+			}
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v31/github"
+)
+
+func TestGroupReleaseNotes(t *testing.T) {
+	pr := func(number int, body string, labels ...string) *github.PullRequest {
+		mergedAt := time.Now()
+		pr := &github.PullRequest{
+			Number:   github.Int(number),
+			Body:     github.String(body),
+			MergedAt: &mergedAt,
+		}
+		for _, label := range labels {
+			pr.Labels = append(pr.Labels, &github.Label{Name: github.String(label)})
+		}
+		return pr
+	}
+
+	prs := []*github.PullRequest{
+		pr(1, "```release-note\nAdded the /hold command.\n```", "kind/feature"),
+		pr(2, "```release-note\nNONE\n```", "kind/bug"),
+		pr(3, "```release-note\nFixed a crash.\n```", "kind/bug", "kind/cleanup"),
+		pr(4, "```release-note\nAction required: rename the setting.\n```", "kind/api-change"),
+		pr(5, "no release note", "kind/bug"),
+		pr(6, "```release-note\nUpdated a dependency.\n```", "kind/chore"),
+		pr(7, "```release-note\nSomething.\n```"),
+	}
+
+	var notes []*ReleaseNote
+	for _, pr := range prs {
+		if note := releaseNoteOf(pr); note != nil {
+			notes = append(notes, note)
+		}
+	}
+
+	sections := groupReleaseNotes(notes)
+	var titles []string
+	for _, section := range sections {
+		titles = append(titles, section.Title)
+	}
+	expected := "Action Required, Features, Bug Fixes, chore, Uncategorized"
+	if strings.Join(titles, ", ") != expected {
+		t.Fatalf("expected the sections %s, got %v", expected, titles)
+	}
+	if sections[0].Notes[0].Number != 4 || sections[2].Notes[0].Number != 3 {
+		t.Fatalf("unexpected notes in the sections: %+v", sections)
+	}
+
+	markdown := (&ReleaseNotes{Org: "org", Repo: "repo", From: "v1", To: "v2", Sections: sections}).Markdown()
+	if !strings.Contains(markdown, "## Bug Fixes\n\n- Fixed a crash. ([#3]") {
+		t.Fatalf("unexpected markdown:\n%s", markdown)
+	}
+}
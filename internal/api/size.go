@@ -0,0 +1,100 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/mattermost/chewbacca/internal/utils"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// sizeLabelColors are the colors of the size labels created by the bot.
+var sizeLabelColors = map[string]string{
+	"size/XS":  "009900",
+	"size/S":   "77bb00",
+	"size/M":   "eebb00",
+	"size/L":   "ee9900",
+	"size/XL":  "ee5500",
+	"size/XXL": "ee0000",
+}
+
+// sizePlugin labels the pull requests with their size, computed from the number of
+// changed lines.
+type sizePlugin struct{}
+
+func (p *sizePlugin) Name() string {
+	return "size"
+}
+
+func (p *sizePlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypePullRequest: {model.PullRequestActionOpened, model.PullRequestActionSynchronize},
+	}
+}
+
+func (p *sizePlugin) Handle(c *Context, e *Event) error {
+	sizeConfig := c.RepoConfig(e.Org, e.Repo).Size
+
+	files, err := c.GitHub.ListPullRequestFiles(e.Org, e.Repo, e.Number)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the files of PR#%d", e.Number)
+	}
+
+	lines := 0
+	for _, file := range files {
+		if utils.MatchAnyGlob(sizeConfig.Excludes, file.GetFilename()) {
+			continue
+		}
+		lines += file.GetAdditions() + file.GetDeletions()
+	}
+	label := sizeConfig.Label(lines)
+
+	labels, err := c.GitHub.GetIssueLabels(e.Org, e.Repo, e.Number)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the labels of PR#%d", e.Number)
+	}
+	currentLabels := utils.LabelsSet(labels)
+
+	err = removeOtherLabels(
+		func(l string) error {
+			return c.GitHub.RemoveLabel(e.Org, e.Repo, e.Number, l)
+		},
+		label,
+		sizeConfig.Labels(),
+		currentLabels,
+	)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to remove the stale size labels")
+	}
+
+	if currentLabels.Has(label) {
+		return nil
+	}
+
+	if err := ensureLabel(c, e.Org, e.Repo, label, "Denotes the size of a PR, from its changed lines.", sizeLabelColors[label]); err != nil {
+		c.Logger.WithError(err).Error("Failed to create label")
+	}
+
+	c.Logger.Infof("adding the %s label for %d changed lines", label, lines)
+	return c.GitHub.AddLabels(e.Org, e.Repo, e.Number, []string{label})
+}
+
+// ensureLabel creates the label in the repository if it doesn't exist yet.
+func ensureLabel(c *Context, org, repo, name, description, color string) error {
+	repoLabels, err := c.GitHub.ListRepoLabels(org, repo)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list repo labels on repo %s", repo)
+	}
+
+	existing := sets.New[string]()
+	for _, l := range repoLabels {
+		existing.Insert(strings.ToLower(l.GetName()))
+	}
+	if existing.Has(strings.ToLower(name)) {
+		return nil
+	}
+
+	return c.GitHub.CreateLabel(org, repo, buildGhLabel(name, description, color))
+}
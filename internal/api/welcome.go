@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/mattermost/chewbacca/internal/utils"
+	"github.com/mattermost/chewbacca/model"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	firstTimeContributorLabel = "first-time-contributor"
+
+	defaultWelcomeMessage = `welcome and thank you for your first contribution! :tada:
+
+A maintainer will review your PR soon. In the meantime:
+
+- Please make sure the PR description has a release-note block, as described in our [release note process](%s).
+- You can interact with the bot with the commands listed [here](%s).`
+)
+
+// welcomePlugin welcomes the contributors opening their first PR in the repository.
+type welcomePlugin struct{}
+
+func (p *welcomePlugin) Name() string {
+	return "welcome"
+}
+
+func (p *welcomePlugin) Events() map[string][]string {
+	return map[string][]string{
+		model.EventTypePullRequest: {model.PullRequestActionOpened},
+	}
+}
+
+func (p *welcomePlugin) Handle(c *Context, e *Event) error {
+	user := e.PullRequest.GetPullRequest().GetUser()
+	if user.GetType() == "Bot" {
+		return nil
+	}
+	author := user.GetLogin()
+
+	isMember, err := c.GitHub.IsMember(e.Org, author)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the membership")
+	}
+	if isMember {
+		return nil
+	}
+
+	merged, err := c.GitHub.CountMergedPullRequests(e.Org, e.Repo, author)
+	if err != nil {
+		return errors.Wrap(err, "failed to count the merged PRs of the author")
+	}
+	if merged > 0 {
+		return nil
+	}
+
+	// When the comment fails, the plugin is retried from the start. The PR isn't merged
+	// yet so it's still a first contribution, and adding the label again is a no-op.
+	c.Logger.Infof("welcoming the first-time contributor %s", author)
+	if err := c.GitHub.AddLabels(e.Org, e.Repo, e.Number, []string{firstTimeContributorLabel}); err != nil {
+		return errors.Wrapf(err, "failed to add the %s label", firstTimeContributorLabel)
+	}
+
+	message := c.RepoConfig(e.Org, e.Repo).WelcomeMessage
+	if message == "" {
+		message = fmt.Sprintf(defaultWelcomeMessage, utils.ReleaseNoteProcessURL(), utils.CommandHelpURL())
+	}
+
+	return c.GitHub.CreateComment(e.Org, e.Repo, e.Number, utils.FormatSimpleResponse(author, message))
+}
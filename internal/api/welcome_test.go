@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mattermost/chewbacca/model"
+)
+
+// failingCommentGitHub fails the given number of comments.
+type failingCommentGitHub struct {
+	*FakeGitHub
+	failures int
+}
+
+func (g *failingCommentGitHub) CreateComment(org, repo string, number int, comment string) error {
+	if g.failures > 0 {
+		g.failures--
+		return errors.New("failed to comment")
+	}
+	return g.FakeGitHub.CreateComment(org, repo, number, comment)
+}
+
+func TestWelcome(t *testing.T) {
+	testCases := []struct {
+		name       string
+		author     string
+		wantLabels []string
+		wantCalls  []string
+	}{
+		{"first-time contributor", "alice", []string{firstTimeContributorLabel}, []string{"AddLabels", "CreateComment"}},
+		{"member", "bob", nil, nil},
+		{"bot", "dependabot[bot]", nil, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, fake, recorder := newTestContext("welcome", "bob")
+			payload := testPullRequestPayload(model.PullRequestActionOpened, tc.author)
+			if err := processTestEvent(t, c, fake, model.EventTypePullRequest, payload); err != nil {
+				t.Fatal(err)
+			}
+
+			if labels := labelNames(fake); !reflect.DeepEqual(labels, tc.wantLabels) {
+				t.Errorf("expected the labels %v, got %v", tc.wantLabels, labels)
+			}
+			if calls := actionNames(recorder.Actions("org", "repo")); !reflect.DeepEqual(calls, tc.wantCalls) {
+				t.Errorf("expected the changes %v, got %v", tc.wantCalls, calls)
+			}
+		})
+	}
+}
+
+// TestWelcomeRetry checks that a failed comment is retried by running the whole plugin
+// again, adding the label a second time, which GitHub ignores.
+func TestWelcomeRetry(t *testing.T) {
+	c, fake, recorder := newTestContext("welcome")
+	c.GitHub = &failingCommentGitHub{FakeGitHub: fake, failures: 1}
+
+	payload := testPullRequestPayload(model.PullRequestActionOpened, "alice")
+	fake.Observe(model.EventTypePullRequest, payload)
+	failed, err := ProcessEvent(c, model.EventTypePullRequest, payload, nil)
+	if err == nil || !reflect.DeepEqual(failed, []string{"welcome"}) {
+		t.Fatalf("expected the welcome plugin to fail, got %v", failed)
+	}
+
+	if _, err := ProcessEvent(c, model.EventTypePullRequest, payload, failed); err != nil {
+		t.Fatal(err)
+	}
+
+	if labels := labelNames(fake); !reflect.DeepEqual(labels, []string{firstTimeContributorLabel}) {
+		t.Errorf("expected the %s label once, got %v", firstTimeContributorLabel, labels)
+	}
+	expected := []string{"AddLabels", "AddLabels", "CreateComment"}
+	if calls := actionNames(recorder.Actions("org", "repo")); !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected the changes %v, got %v", expected, calls)
+	}
+}
@@ -0,0 +1,179 @@
+// Package archive stores the raw webhooks received from GitHub on disk, so they can be
+// replayed to reproduce the behaviour of the bot.
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const timeFormat = "20060102T150405.000000000Z"
+
+// unsafeRe matches the characters not kept in the file names.
+var unsafeRe = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// Record is a webhook as received from GitHub.
+type Record struct {
+	ReceivedAt time.Time       `json:"received_at"`
+	Headers    http.Header     `json:"headers"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// EventType returns the type of the webhook event.
+func (r *Record) EventType() string {
+	return r.Headers.Get("X-GitHub-Event")
+}
+
+// DeliveryID returns the ID of the webhook delivery.
+func (r *Record) DeliveryID() string {
+	return r.Headers.Get("X-GitHub-Delivery")
+}
+
+// Options configures the retention of the archive.
+type Options struct {
+	// MaxAge is how long the webhooks are kept. They are kept forever when zero.
+	MaxAge time.Duration
+	// MaxFiles is the number of webhooks kept, the oldest ones are removed first.
+	// There is no limit when zero.
+	MaxFiles int
+}
+
+// Archive writes the webhooks in a directory, one JSON file each.
+type Archive struct {
+	dir     string
+	options Options
+}
+
+// New creates an archive writing in the directory, creating it if needed.
+func New(dir string, options Options) (*Archive, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, errors.Wrapf(err, "failed to create the archive directory %s", dir)
+	}
+	return &Archive{dir: dir, options: options}, nil
+}
+
+// Write archives the webhook and removes the webhooks exceeding the retention. The file
+// names start with the reception time, so they sort in the order the webhooks were
+// received.
+func (a *Archive) Write(headers http.Header, body []byte) error {
+	if !json.Valid(body) {
+		return errors.New("the webhook body is not valid JSON")
+	}
+
+	record := &Record{
+		ReceivedAt: time.Now().UTC(),
+		Headers:    headers,
+		Body:       body,
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the webhook")
+	}
+
+	name := fmt.Sprintf("%s-%s-%s.json",
+		record.ReceivedAt.Format(timeFormat),
+		unsafeRe.ReplaceAllString(record.EventType(), "_"),
+		unsafeRe.ReplaceAllString(record.DeliveryID(), "_"),
+	)
+	if err := os.WriteFile(filepath.Join(a.dir, name), data, 0o640); err != nil {
+		return errors.Wrapf(err, "failed to write the webhook %s", name)
+	}
+
+	return a.prune(record.ReceivedAt)
+}
+
+// prune removes the oldest webhooks beyond the maximum number of files, and the ones
+// received before the maximum age. The other files of the directory are left as is.
+func (a *Archive) prune(now time.Time) error {
+	if a.options.MaxAge <= 0 && a.options.MaxFiles <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(a.dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the webhooks in %s", a.dir)
+	}
+
+	// The entries are sorted by name, so from the oldest to the latest webhook.
+	var names []string
+	var receivedAt []time.Time
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") || len(name) < len(timeFormat) {
+			continue
+		}
+		t, err := time.Parse(timeFormat, name[:len(timeFormat)])
+		if err != nil {
+			continue
+		}
+		names = append(names, name)
+		receivedAt = append(receivedAt, t)
+	}
+
+	remove := 0
+	if a.options.MaxFiles > 0 && len(names) > a.options.MaxFiles {
+		remove = len(names) - a.options.MaxFiles
+	}
+	if a.options.MaxAge > 0 {
+		for remove < len(names) && now.Sub(receivedAt[remove]) > a.options.MaxAge {
+			remove++
+		}
+	}
+
+	for _, name := range names[:remove] {
+		if err := os.Remove(filepath.Join(a.dir, name)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove the webhook %s", name)
+		}
+	}
+
+	return nil
+}
+
+// Load reads the webhooks archived in the file, or in all the JSON files of the
+// directory sorted by name.
+func Load(path string) ([]*Record, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the webhooks in %s", path)
+		}
+		files = nil
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var records []*Record
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the webhook %s", file)
+		}
+
+		record := &Record{}
+		if err := json.Unmarshal(data, record); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the webhook %s", file)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,91 @@
+package archive_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/archive"
+)
+
+func TestArchive(t *testing.T) {
+	dir := t.TempDir()
+	a, err := archive.New(dir, archive.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, event := range []string{"pull_request", "issue_comment"} {
+		headers := http.Header{}
+		headers.Set("X-GitHub-Event", event)
+		headers.Set("X-GitHub-Delivery", "delivery-"+string(rune('a'+i)))
+		if err := a.Write(headers, []byte(`{"action":"opened"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.Write(http.Header{}, []byte("payload=not-json")); err == nil {
+		t.Fatal("expected an error for a body which is not JSON")
+	}
+
+	records, err := archive.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].EventType() != "pull_request" || records[1].DeliveryID() != "delivery-b" {
+		t.Fatalf("expected the webhooks in the reception order, got %+v", records)
+	}
+	var body struct{ Action string }
+	if err := json.Unmarshal(records[0].Body, &body); err != nil || body.Action != "opened" {
+		t.Fatalf("unexpected body %s", records[0].Body)
+	}
+
+	files, _ := os.ReadDir(dir)
+	records, err = archive.Load(filepath.Join(dir, files[1].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].EventType() != "issue_comment" {
+		t.Fatalf("expected the webhook of the file, got %+v", records)
+	}
+}
+
+func TestArchiveRetention(t *testing.T) {
+	dir := t.TempDir()
+	old := "20200101T000000.000000000Z-pull_request-old.json"
+	for _, name := range []string{old, "notes.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o640); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a, err := archive.New(dir, archive.Options{MaxAge: 24 * time.Hour, MaxFiles: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, delivery := range []string{"a", "b", "c"} {
+		headers := http.Header{}
+		headers.Set("X-GitHub-Event", "pull_request")
+		headers.Set("X-GitHub-Delivery", delivery)
+		if err := a.Write(headers, []byte(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, old)); !os.IsNotExist(err) {
+		t.Fatal("expected the webhook older than the maximum age to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.json")); err != nil {
+		t.Fatal("expected the files which are not webhooks to be kept")
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "2*.json"))
+	if len(files) != 2 {
+		t.Fatalf("expected the 2 latest webhooks to be kept, got %v", files)
+	}
+	records, err := archive.Load(files[1])
+	if err != nil || records[0].DeliveryID() != "c" {
+		t.Fatalf("expected the latest webhook to be kept, got %v", files)
+	}
+}
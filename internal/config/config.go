@@ -0,0 +1,291 @@
+// Package config handles the per-repository configuration that teams keep in a
+// .chewbacca.yaml file on the default branch of their repository.
+package config
+
+import (
+	"strings"
+
+	"github.com/mattermost/chewbacca/internal/utils"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FileName is the name of the configuration file looked up in each repository.
+	FileName = ".chewbacca.yaml"
+	// OrgRepo is the repository holding the organization-wide fallback configuration.
+	OrgRepo = ".github"
+)
+
+// BranchPrefix maps a branch name prefix to the label applied to the PR.
+type BranchPrefix struct {
+	Prefix      string `yaml:"prefix"`
+	Label       string `yaml:"label"`
+	Color       string `yaml:"color"`
+	Description string `yaml:"description"`
+}
+
+// BlockRule describes when a PR is blocked from being merged. The rule blocks the PR
+// when any of its conditions is met.
+type BlockRule struct {
+	// Name identifies the rule in the merge blocker status.
+	Name string `yaml:"name"`
+	// Message explains how to clear the blocker. A default message is used when empty.
+	Message string `yaml:"message"`
+	// Branches restricts the rule to the PRs targeting the base branches matching
+	// these patterns.
+	Branches []string `yaml:"branches"`
+	// Labels are the labels blocking the merge.
+	Labels []string `yaml:"labels"`
+	// LabelPatterns are patterns of labels blocking the merge, like do-not-merge/*.
+	LabelPatterns []string `yaml:"labelPatterns"`
+	// RequiredLabels are the labels which must be set on the PR.
+	RequiredLabels []string `yaml:"requiredLabels"`
+	// Draft blocks the draft PRs.
+	Draft bool `yaml:"draft"`
+	// TitlePrefixes block the PRs whose title starts with one of them, ignoring case.
+	TitlePrefixes []string `yaml:"titlePrefixes"`
+	// MissingMilestone blocks the PRs without milestone.
+	MissingMilestone bool `yaml:"missingMilestone"`
+	// ConventionalTitle blocks the PRs whose title doesn't follow the conventional
+	// commits format with one of the title types.
+	ConventionalTitle bool `yaml:"conventionalTitle"`
+}
+
+// AreaLabel maps the file paths matching the globs to a label.
+type AreaLabel struct {
+	Label string `yaml:"label"`
+	// Paths are glob patterns of the files, like server/app/**.
+	Paths []string `yaml:"paths"`
+}
+
+// TitleType maps a conventional commit type of the PR titles, like feat in
+// `feat(api): add an endpoint`, to its kind label.
+type TitleType struct {
+	Type  string `yaml:"type"`
+	Label string `yaml:"label"`
+}
+
+// SizeConfig configures the size/* labels computed from the number of changed lines.
+type SizeConfig struct {
+	// S, M, L, XL and XXL are the minimum numbers of changed lines of the size/S to
+	// size/XXL labels. The smaller PRs are size/XS.
+	S   int `yaml:"s"`
+	M   int `yaml:"m"`
+	L   int `yaml:"l"`
+	XL  int `yaml:"xl"`
+	XXL int `yaml:"xxl"`
+	// Excludes are the glob patterns of the files not counted, like vendor/**.
+	Excludes []string `yaml:"excludes"`
+}
+
+// Labels returns all the size labels, from the smallest to the largest.
+func (s SizeConfig) Labels() []string {
+	return []string{"size/XS", "size/S", "size/M", "size/L", "size/XL", "size/XXL"}
+}
+
+// Label returns the size label of a PR changing the given number of lines.
+func (s SizeConfig) Label(lines int) string {
+	labels := s.Labels()
+	thresholds := []int{s.S, s.M, s.L, s.XL, s.XXL}
+	label := labels[0]
+	for i, threshold := range thresholds {
+		if lines >= threshold {
+			label = labels[i+1]
+		}
+	}
+	return label
+}
+
+// LifecycleConfig configures the inactivity windows after which the open issues and
+// PRs are marked as stale, then rotten, and finally closed.
+type LifecycleConfig struct {
+	// StaleAfterDays is the number of inactive days before being marked as stale.
+	StaleAfterDays int `yaml:"staleAfterDays"`
+	// RottenAfterDays is the number of inactive days of a stale issue before being
+	// marked as rotten.
+	RottenAfterDays int `yaml:"rottenAfterDays"`
+	// CloseAfterDays is the number of inactive days of a rotten issue before being closed.
+	CloseAfterDays int `yaml:"closeAfterDays"`
+}
+
+// RepoConfig is the configuration of a single repository.
+type RepoConfig struct {
+	// AdditionalLabels are the labels that can be set with the /label command.
+	AdditionalLabels []string `yaml:"additionalLabels"`
+	// BranchPrefixes are used to label a PR based on its head branch name.
+	BranchPrefixes []BranchPrefix `yaml:"branchPrefixes"`
+	// BlockingLabels are the labels that block a PR from being merged.
+	BlockingLabels []string `yaml:"blockingLabels"`
+	// Blockers are the rules blocking a PR from being merged, in addition to the
+	// blocking labels.
+	Blockers []BlockRule `yaml:"blockers"`
+	// Plugins are the names of the plugins enabled for the repository.
+	Plugins []string `yaml:"plugins"`
+	// RequestedReviewers is the number of reviewers requested from the OWNERS files
+	// when a PR is opened.
+	RequestedReviewers int `yaml:"requestedReviewers"`
+	// Size configures the size/* labels.
+	Size SizeConfig `yaml:"size"`
+	// AreaLabels are the labels applied to the PRs changing the matching files.
+	AreaLabels []AreaLabel `yaml:"areaLabels"`
+	// RemoveStaleAreaLabels removes the area labels which no longer match the files
+	// changed by the PR, like after a force-push.
+	RemoveStaleAreaLabels bool `yaml:"removeStaleAreaLabels"`
+	// Lifecycle configures the lifecycle of the inactive issues and PRs.
+	Lifecycle LifecycleConfig `yaml:"lifecycle"`
+	// TitleTypes are the conventional commit types allowed in the PR titles.
+	TitleTypes []TitleType `yaml:"titleTypes"`
+	// BreakingChangeLabel is applied to the PRs whose title marks a breaking change,
+	// like `feat!: remove an endpoint`.
+	BreakingChangeLabel string `yaml:"breakingChangeLabel"`
+	// WelcomeMessage is posted on the first PR of a contributor. A default message is
+	// used when empty.
+	WelcomeMessage string `yaml:"welcomeMessage"`
+	// DryRun records the changes of the plugins, viewable with the admin endpoints,
+	// instead of making them.
+	DryRun bool `yaml:"dryRun"`
+}
+
+// Default returns the configuration used when no configuration file is found.
+func Default() *RepoConfig {
+	return &RepoConfig{
+		AdditionalLabels: []string{
+			"kind/bug",
+			"kind/feature",
+			"kind/cleanup",
+			"kind/api-change",
+			"kind/design",
+			"kind/regression",
+			"kind/documentation",
+			"priority/critical-urgent",
+			"priority/important-longterm",
+			"priority/important-soon",
+		},
+		BranchPrefixes: []BranchPrefix{
+			{Prefix: "feat/", Label: "kind/feature", Color: "c7def8", Description: "Categorizes issue or PR as related to a new feature."},
+			{Prefix: "fix/", Label: "kind/bug", Color: "e11d21", Description: "Categorizes issue or PR as related to a bug."},
+			{Prefix: "test/", Label: "kind/testing", Color: "79D6D6", Description: "Categorizes issue or PR as related to addition or refactoring of tests."},
+			{Prefix: "chore/", Label: "kind/chore", Color: "c7def8", Description: "Categorizes issue or PR as related to updates that are not production code."},
+			{Prefix: "refactor/", Label: "kind/refactor", Color: "c7def8", Description: "Categorizes issue or PR as related to refactor of production code."},
+		},
+		BlockingLabels: []string{
+			"do-not-merge",
+			"do-not-merge/awaiting-PR",
+			"do-not-merge/hold",
+			"needs-rebase",
+			"do-not-merge/awaiting-submitter-action",
+			"do-not-merge/work-in-progress",
+			"do-not-merge/release-note-label-needed",
+			"release-note-action-required",
+			"WIP",
+		},
+		Plugins: []string{
+			"release-notes",
+			"label",
+			"blocker",
+		},
+		RequestedReviewers: 2,
+		Size: SizeConfig{
+			S:   10,
+			M:   30,
+			L:   100,
+			XL:  500,
+			XXL: 1000,
+			Excludes: []string{
+				"vendor/**",
+				"**/*.pb.go",
+				"**/go.sum",
+				"**/package-lock.json",
+			},
+		},
+		TitleTypes: []TitleType{
+			{Type: "feat", Label: "kind/feature"},
+			{Type: "fix", Label: "kind/bug"},
+			{Type: "docs", Label: "kind/documentation"},
+			{Type: "test", Label: "kind/testing"},
+			{Type: "refactor", Label: "kind/refactor"},
+			{Type: "perf", Label: "kind/refactor"},
+			{Type: "chore", Label: "kind/chore"},
+			{Type: "build", Label: "kind/chore"},
+			{Type: "ci", Label: "kind/chore"},
+			{Type: "revert", Label: "kind/chore"},
+		},
+		BreakingChangeLabel: "kind/breaking-change",
+		Lifecycle: LifecycleConfig{
+			StaleAfterDays:  90,
+			RottenAfterDays: 30,
+			CloseAfterDays:  30,
+		},
+	}
+}
+
+// Parse decodes the given configuration files on top of the default
+// configuration. Later files override the fields they set in earlier ones.
+func Parse(files ...[]byte) (*RepoConfig, error) {
+	cfg := Default()
+	for _, data := range files {
+		if len(data) == 0 {
+			continue
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, errors.Wrap(err, "failed to parse the configuration")
+		}
+	}
+
+	return cfg, nil
+}
+
+// BlockRules returns the rules blocking a PR from being merged, the blocking labels
+// being turned into one rule each.
+func (c *RepoConfig) BlockRules() []BlockRule {
+	var rules []BlockRule
+	for _, label := range c.BlockingLabels {
+		rules = append(rules, BlockRule{Name: label, Labels: []string{label}})
+	}
+	return append(rules, c.Blockers...)
+}
+
+// TitleTypeLabel returns the kind label of the conventional commit type, and whether
+// the type is allowed.
+func (c *RepoConfig) TitleTypeLabel(titleType string) (string, bool) {
+	for _, t := range c.TitleTypes {
+		if strings.EqualFold(t.Type, titleType) {
+			return t.Label, true
+		}
+	}
+	return "", false
+}
+
+// PluginEnabled returns whether the plugin is enabled for the repository.
+func (c *RepoConfig) PluginEnabled(name string) bool {
+	for _, plugin := range c.Plugins {
+		if plugin == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FileAreaLabels returns the area labels matching the given file.
+func (c *RepoConfig) FileAreaLabels(file string) []string {
+	var labels []string
+	for _, area := range c.AreaLabels {
+		if utils.MatchAnyGlob(area.Paths, file) {
+			labels = append(labels, area.Label)
+		}
+	}
+	return labels
+}
+
+// BranchLabels returns the branch prefixes found in the given branch name.
+func (c *RepoConfig) BranchLabels(branch string) []BranchPrefix {
+	var matches []BranchPrefix
+	for _, p := range c.BranchPrefixes {
+		if p.Prefix != "" && strings.Contains(branch, p.Prefix) {
+			matches = append(matches, p)
+		}
+	}
+	return matches
+}
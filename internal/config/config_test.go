@@ -0,0 +1,114 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/chewbacca/internal/config"
+)
+
+func TestParse(t *testing.T) {
+	orgFile := []byte(`
+additionalLabels:
+  - kind/bug
+blockingLabels:
+  - do-not-merge
+`)
+	repoFile := []byte(`
+blockingLabels:
+  - do-not-merge
+  - do-not-merge/hold
+`)
+
+	cfg, err := config.Parse(orgFile, repoFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cfg.AdditionalLabels) != 1 || cfg.AdditionalLabels[0] != "kind/bug" {
+		t.Fatalf("expected the org additional labels, got %v", cfg.AdditionalLabels)
+	}
+	if len(cfg.BlockingLabels) != 2 {
+		t.Fatalf("expected the repo blocking labels, got %v", cfg.BlockingLabels)
+	}
+	if len(cfg.BranchPrefixes) != len(config.Default().BranchPrefixes) {
+		t.Fatalf("expected the default branch prefixes, got %v", cfg.BranchPrefixes)
+	}
+
+	if _, err := config.Parse([]byte("additionalLabels: {")); err == nil {
+		t.Fatal("expected an error for an invalid file")
+	}
+}
+
+func TestBranchLabels(t *testing.T) {
+	cfg := config.Default()
+
+	labels := cfg.BranchLabels("user/fix/MM-1234")
+	if len(labels) != 1 || labels[0].Label != "kind/bug" {
+		t.Fatalf("expected kind/bug, got %v", labels)
+	}
+
+	if labels := cfg.BranchLabels("master"); len(labels) != 0 {
+		t.Fatalf("expected no labels, got %v", labels)
+	}
+}
+
+func TestSizeLabel(t *testing.T) {
+	size := config.Default().Size
+
+	for lines, want := range map[int]string{0: "size/XS", 9: "size/XS", 10: "size/S", 99: "size/M", 500: "size/XL", 5000: "size/XXL"} {
+		if got := size.Label(lines); got != want {
+			t.Errorf("expected %s for %d lines, got %s", want, lines, got)
+		}
+	}
+}
+
+func TestFileAreaLabels(t *testing.T) {
+	cfg, err := config.Parse([]byte(`
+areaLabels:
+- label: area/app
+  paths:
+  - server/app/**
+- label: area/server
+  paths:
+  - server/**
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if labels := cfg.FileAreaLabels("server/app/post.go"); len(labels) != 2 {
+		t.Fatalf("expected area/app and area/server, got %v", labels)
+	}
+	if labels := cfg.FileAreaLabels("webapp/index.js"); len(labels) != 0 {
+		t.Fatalf("expected no labels, got %v", labels)
+	}
+}
+
+func TestParseLabelManifest(t *testing.T) {
+	manifest, err := config.ParseLabelManifest([]byte(`
+labels:
+- name: kind/bug
+  color: "#E11D21"
+- name: kind/cleanup
+  color: c7def8
+  previously:
+  - cleanup
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Labels) != 2 || manifest.Labels[0].Color != "e11d21" {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+
+	invalid := map[string]string{
+		"invalid color":    "labels:\n- name: kind/bug\n  color: red\n",
+		"duplicated name":  "labels:\n- name: kind/bug\n  color: e11d21\n- name: Kind/Bug\n  color: e11d21\n",
+		"duplicated alias": "labels:\n- name: kind/bug\n  color: e11d21\n- name: kind/cleanup\n  color: c7def8\n  previously: [kind/bug]\n",
+	}
+	for name, data := range invalid {
+		if _, err := config.ParseLabelManifest([]byte(data)); err == nil {
+			t.Errorf("expected an error for the %s", name)
+		}
+	}
+}
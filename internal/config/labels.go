@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var labelColorRe = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// Label is a label declared in the label manifest.
+type Label struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Color is the hexadecimal color of the label, without the leading #.
+	Color string `yaml:"color"`
+	// Previously are the former names of the label. A label with one of these names
+	// is renamed, keeping it on the issues and PRs.
+	Previously []string `yaml:"previously"`
+}
+
+// LabelManifest declares the labels of all the repositories of an organization.
+type LabelManifest struct {
+	Labels []Label `yaml:"labels"`
+}
+
+// LoadLabelManifest reads and parses the label manifest file.
+func LoadLabelManifest(path string) (*LabelManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the label manifest %s", path)
+	}
+	return ParseLabelManifest(data)
+}
+
+// ParseLabelManifest decodes the label manifest and checks that the colors are valid
+// and that each name, current or previous, is declared once. The names are compared
+// ignoring case, like GitHub does.
+func ParseLabelManifest(data []byte) (*LabelManifest, error) {
+	manifest := &LabelManifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the label manifest")
+	}
+
+	names := make(map[string]bool)
+	declare := func(name string) error {
+		key := strings.ToLower(name)
+		if names[key] {
+			return errors.Errorf("the label %q is declared more than once", name)
+		}
+		names[key] = true
+		return nil
+	}
+
+	for i := range manifest.Labels {
+		label := &manifest.Labels[i]
+		label.Color = strings.ToLower(strings.TrimPrefix(label.Color, "#"))
+
+		if label.Name == "" {
+			return nil, errors.Errorf("the label #%d has no name", i+1)
+		}
+		if !labelColorRe.MatchString(label.Color) {
+			return nil, errors.Errorf("the label %q has an invalid color %q", label.Name, label.Color)
+		}
+		if err := declare(label.Name); err != nil {
+			return nil, err
+		}
+		for _, previous := range label.Previously {
+			if err := declare(previous); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return manifest, nil
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// FileGetter describes the interface required to fetch the configuration files.
+type FileGetter interface {
+	// GetFileContent returns the content of a file from the given ref, or from the
+	// default branch when ref is empty. It returns nil content if the file doesn't exist.
+	GetFileContent(org, repo, path, ref string) ([]byte, error)
+}
+
+type cacheEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// Store loads and caches the repository configurations.
+type Store struct {
+	getter FileGetter
+	ttl    time.Duration
+	logger log.FieldLogger
+
+	mu    sync.Mutex
+	files map[string]cacheEntry
+}
+
+// NewStore creates a new configuration store caching the files for the given duration.
+func NewStore(getter FileGetter, ttl time.Duration, logger log.FieldLogger) *Store {
+	return &Store{
+		getter: getter,
+		ttl:    ttl,
+		logger: logger,
+		files:  make(map[string]cacheEntry),
+	}
+}
+
+// Get returns the configuration of a repository, layering the repository file on
+// top of the organization-wide fallback file and the defaults.
+func (s *Store) Get(org, repo string) (*RepoConfig, error) {
+	orgFile, err := s.file(org, OrgRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	repoFile, err := s.file(org, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(orgFile, repoFile)
+}
+
+// Invalidate drops the cached configuration file of a repository.
+func (s *Store) Invalidate(org, repo string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.files, org+"/"+repo)
+}
+
+func (s *Store) file(org, repo string) ([]byte, error) {
+	key := org + "/" + repo
+
+	s.mu.Lock()
+	entry, ok := s.files[key]
+	s.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.data, nil
+	}
+
+	s.logger.WithField("repo", key).Debug("Fetching the repository configuration")
+	data, err := s.getter.GetFileContent(org, repo, FileName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.files[key] = cacheEntry{data: data, expires: time.Now().Add(s.ttl)}
+	s.mu.Unlock()
+
+	return data, nil
+}
@@ -0,0 +1,82 @@
+// Package dedup keeps track of the webhook deliveries already received to skip the
+// ones redelivered by GitHub.
+package dedup
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type delivery struct {
+	id       string
+	received time.Time
+}
+
+// Store records the delivery IDs for a limited time and up to a maximum number of
+// deliveries, evicting the oldest ones first.
+type Store struct {
+	ttl     time.Duration
+	maxSize int
+	now     func() time.Time
+
+	mu         sync.Mutex
+	order      *list.List
+	deliveries map[string]*list.Element
+}
+
+// NewStore creates a new delivery store.
+func NewStore(ttl time.Duration, maxSize int) *Store {
+	return &Store{
+		ttl:        ttl,
+		maxSize:    maxSize,
+		now:        time.Now,
+		order:      list.New(),
+		deliveries: make(map[string]*list.Element),
+	}
+}
+
+// Seen records the delivery and reports whether it was already recorded.
+func (s *Store) Seen(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.evict(now)
+
+	if _, ok := s.deliveries[id]; ok {
+		return true
+	}
+
+	s.deliveries[id] = s.order.PushBack(&delivery{id: id, received: now})
+	if s.maxSize > 0 && s.order.Len() > s.maxSize {
+		s.remove(s.order.Front())
+	}
+
+	return false
+}
+
+// Forget removes a delivery so it is processed again if redelivered.
+func (s *Store) Forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.deliveries[id]; ok {
+		s.remove(element)
+	}
+}
+
+// evict removes the deliveries older than the TTL.
+func (s *Store) evict(now time.Time) {
+	for element := s.order.Front(); element != nil; element = s.order.Front() {
+		if now.Sub(element.Value.(*delivery).received) < s.ttl {
+			return
+		}
+		s.remove(element)
+	}
+}
+
+func (s *Store) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.deliveries, element.Value.(*delivery).id)
+}
@@ -0,0 +1,45 @@
+package dedup
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	now := time.Now()
+	store := NewStore(time.Minute, 2)
+	store.now = func() time.Time { return now }
+
+	if store.Seen("a") {
+		t.Fatal("expected a new delivery")
+	}
+	if !store.Seen("a") {
+		t.Fatal("expected the delivery to be already seen")
+	}
+
+	// The oldest delivery is evicted above the maximum size.
+	now = now.Add(time.Second)
+	store.Seen("b")
+	store.Seen("c")
+	if store.Seen("a") {
+		t.Fatal("expected the oldest delivery to be evicted")
+	}
+	if !store.Seen("c") {
+		t.Fatal("expected the latest delivery to be kept")
+	}
+
+	// A forgotten delivery is processed again.
+	store.Forget("c")
+	if store.Seen("c") {
+		t.Fatal("expected the forgotten delivery to be new")
+	}
+
+	// The deliveries expire after the TTL.
+	now = now.Add(time.Minute)
+	if store.Seen("c") {
+		t.Fatal("expected the delivery to be expired")
+	}
+	if len(store.deliveries) != 1 || store.order.Len() != 1 {
+		t.Fatalf("expected only the new delivery to be kept, got %d", len(store.deliveries))
+	}
+}
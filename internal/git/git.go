@@ -0,0 +1,167 @@
+// Package git runs the git CLI in temporary workspaces to change the repositories,
+// like cherry-picking a commit on another branch.
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// credentialsRe matches the credentials of the remote URLs.
+var credentialsRe = regexp.MustCompile(`://[^@/\s]+@`)
+
+// RemoteURLFunc returns the URL, with the credentials, used to clone and push to a
+// repository.
+type RemoteURLFunc func(org, repo string) (string, error)
+
+// ConflictError is returned when a cherry-pick has conflicts.
+type ConflictError struct {
+	// Files are the conflicting files.
+	Files []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflicts in %s", strings.Join(e.Files, ", "))
+}
+
+// Client clones the repositories in temporary workspaces.
+type Client struct {
+	remoteURL      RemoteURLFunc
+	committerName  string
+	committerEmail string
+	logger         log.FieldLogger
+}
+
+// NewClient creates a git client committing as the given committer.
+func NewClient(remoteURL RemoteURLFunc, committerName, committerEmail string, logger log.FieldLogger) *Client {
+	return &Client{
+		remoteURL:      remoteURL,
+		committerName:  committerName,
+		committerEmail: committerEmail,
+		logger:         logger,
+	}
+}
+
+// Repo is a clone of a repository in a temporary workspace, which must be removed
+// with Clean once done.
+type Repo struct {
+	Dir string
+
+	client *Client
+	logger log.FieldLogger
+}
+
+// Clone clones the repository in a new temporary workspace.
+func (c *Client) Clone(org, repo string) (*Repo, error) {
+	remote, err := c.remoteURL(org, repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the URL of %s/%s", org, repo)
+	}
+
+	dir, err := os.MkdirTemp("", "chewbacca-git-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the workspace")
+	}
+
+	r := &Repo{
+		Dir:    dir,
+		client: c,
+		logger: c.logger.WithFields(log.Fields{"org": org, "repo": repo}),
+	}
+	if _, err := r.git("clone", "--quiet", remote, "."); err != nil {
+		r.Clean()
+		return nil, errors.Wrapf(err, "failed to clone %s/%s", org, repo)
+	}
+
+	return r, nil
+}
+
+// Clean removes the workspace.
+func (r *Repo) Clean() error {
+	return os.RemoveAll(r.Dir)
+}
+
+// CheckoutNewBranch creates the branch from the given remote branch and checks it out.
+func (r *Repo) CheckoutNewBranch(branch, from string) error {
+	if _, err := r.git("checkout", "--quiet", "-b", branch, "origin/"+from); err != nil {
+		return errors.Wrapf(err, "failed to create the branch %s from %s", branch, from)
+	}
+	return nil
+}
+
+// CherryPick applies the commit on the current branch. A merge commit is applied
+// relatively to its first parent. A *ConflictError is returned on conflicts, and the
+// cherry-pick is aborted.
+func (r *Repo) CherryPick(sha string) error {
+	parents, err := r.git("rev-list", "--parents", "-n", "1", sha)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the commit %s", sha)
+	}
+
+	args := []string{"cherry-pick"}
+	if len(strings.Fields(parents)) > 2 {
+		args = append(args, "-m", "1")
+	}
+	args = append(args, sha)
+
+	if _, err := r.git(args...); err != nil {
+		conflicts, diffErr := r.git("diff", "--name-only", "--diff-filter=U")
+		if _, abortErr := r.git("cherry-pick", "--abort"); abortErr != nil {
+			r.logger.WithError(abortErr).Warn("failed to abort the cherry-pick")
+		}
+		if diffErr == nil && strings.TrimSpace(conflicts) != "" {
+			return &ConflictError{Files: strings.Fields(conflicts)}
+		}
+		return errors.Wrapf(err, "failed to cherry-pick %s", sha)
+	}
+
+	return nil
+}
+
+// Push force pushes the current branch to the branch of the given repository.
+func (r *Repo) Push(org, repo, branch string) error {
+	remote, err := r.client.remoteURL(org, repo)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the URL of %s/%s", org, repo)
+	}
+
+	if _, err := r.git("push", "--quiet", "--force", remote, "HEAD:refs/heads/"+branch); err != nil {
+		return errors.Wrapf(err, "failed to push %s to %s/%s", branch, org, repo)
+	}
+	return nil
+}
+
+// git runs a git command in the workspace and returns its output.
+func (r *Repo) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		// The cherry-picked commits keep their author.
+		"GIT_COMMITTER_NAME="+r.client.committerName,
+		"GIT_COMMITTER_EMAIL="+r.client.committerEmail,
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	r.logger.WithField("args", args[0]).Debug("Running git")
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), errors.Errorf("git %s failed: %s", args[0], redactCredentials(strings.TrimSpace(stderr.String())))
+	}
+
+	return stdout.String(), nil
+}
+
+// redactCredentials hides the credentials of the remote URLs found in the git output.
+func redactCredentials(output string) string {
+	return credentialsRe.ReplaceAllString(output, "://***@")
+}
@@ -0,0 +1,126 @@
+package git_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/chewbacca/internal/git"
+
+	"github.com/sirupsen/logrus"
+)
+
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Author", "GIT_AUTHOR_EMAIL=author@example.com",
+		"GIT_COMMITTER_NAME=Author", "GIT_COMMITTER_EMAIL=author@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %s", args, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func commit(t *testing.T, dir, file, content string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "add", file)
+	run(t, dir, "commit", "--quiet", "-m", "change "+file)
+	return run(t, dir, "rev-parse", "HEAD")
+}
+
+// setup creates the org/upstream and fork/upstream bare repositories, upstream having
+// a master and a release branch, and returns a client cloning them.
+func setup(t *testing.T) (*git.Client, string, string) {
+	root := t.TempDir()
+	for _, org := range []string{"org", "fork"} {
+		run(t, root, "init", "--quiet", "--bare", filepath.Join(org, "upstream.git"))
+	}
+
+	seed := filepath.Join(root, "seed")
+	run(t, root, "clone", "--quiet", filepath.Join(root, "org", "upstream.git"), seed)
+	run(t, seed, "checkout", "--quiet", "-b", "master")
+	commit(t, seed, "a.txt", "a\n")
+	commit(t, seed, "b.txt", "b\n")
+	run(t, seed, "branch", "release-1.0")
+	clean := commit(t, seed, "c.txt", "c\n")
+	conflicting := commit(t, seed, "b.txt", "b on master\n")
+	run(t, seed, "checkout", "--quiet", "release-1.0")
+	commit(t, seed, "b.txt", "b on release\n")
+	run(t, seed, "push", "--quiet", "origin", "master", "release-1.0")
+
+	client := git.NewClient(func(org, repo string) (string, error) {
+		return filepath.Join(root, org, repo+".git"), nil
+	}, "chewbacca", "chewbacca@example.com", logrus.New())
+
+	return client, clean, conflicting
+}
+
+func TestCherryPick(t *testing.T) {
+	client, clean, _ := setup(t)
+
+	repo, err := client.Clone("org", "upstream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Clean()
+
+	if err := repo.CheckoutNewBranch("cherry-pick-1-to-release-1.0", "release-1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CherryPick(clean); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Push("fork", "upstream", "cherry-pick-1-to-release-1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	pushed, err := client.Clone("fork", "upstream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pushed.Clean()
+	if err := pushed.CheckoutNewBranch("check", "cherry-pick-1-to-release-1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(pushed.Dir, "c.txt")); err != nil {
+		t.Fatalf("expected the cherry-picked file to be pushed: %v", err)
+	}
+	if author := run(t, pushed.Dir, "log", "-1", "--format=%an"); author != "Author" {
+		t.Fatalf("expected the cherry-pick to keep the author, got %s", author)
+	}
+}
+
+func TestCherryPickConflict(t *testing.T) {
+	client, _, conflicting := setup(t)
+
+	repo, err := client.Clone("org", "upstream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Clean()
+
+	if err := repo.CheckoutNewBranch("cherry-pick-2-to-release-1.0", "release-1.0"); err != nil {
+		t.Fatal(err)
+	}
+	err = repo.CherryPick(conflicting)
+	conflict, ok := err.(*git.ConflictError)
+	if !ok {
+		t.Fatalf("expected a conflict error, got %v", err)
+	}
+	if len(conflict.Files) != 1 || conflict.Files[0] != "b.txt" {
+		t.Fatalf("expected a conflict in b.txt, got %v", conflict.Files)
+	}
+
+	if err := repo.CheckoutNewBranch("missing", "release-9.9"); err == nil {
+		t.Fatal("expected an error for a missing branch")
+	}
+}
@@ -0,0 +1,282 @@
+package github
+
+import (
+	"context"
+	"crypto/rsa"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-github/v31/github"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// appTransport authenticates the requests as the GitHub App itself, signing a
+// short-lived JWT with the private key of the app.
+type appTransport struct {
+	appID int64
+	key   *rsa.PrivateKey
+	base  http.RoundTripper
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.jwt()
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return t.base.RoundTrip(req)
+}
+
+// jwt returns a JWT valid for at least one more minute, signing a new one if needed.
+func (t *appTransport) jwt() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if t.token != "" && now.Add(time.Minute).Before(t.expires) {
+		return t.token, nil
+	}
+
+	// GitHub rejects JWTs valid for more than 10 minutes, and the issued time is set
+	// in the past to allow for clock drift.
+	expires := now.Add(9 * time.Minute)
+	claims := jwt.RegisteredClaims{
+		IssuedAt:  jwt.NewNumericDate(now.Add(-time.Minute)),
+		ExpiresAt: jwt.NewNumericDate(expires),
+		Issuer:    strconv.FormatInt(t.appID, 10),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(t.key)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign the GitHub App JWT")
+	}
+
+	t.token = token
+	t.expires = expires
+	return token, nil
+}
+
+// installationTokenSource mints installation access tokens with the app client.
+type installationTokenSource struct {
+	appClient      *github.Client
+	installationID int64
+}
+
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	token, _, err := s.appClient.Apps.CreateInstallationToken(apiContext("CreateInstallationToken", ""), s.installationID, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create a token for the installation %d", s.installationID)
+	}
+
+	return &oauth2.Token{
+		AccessToken: token.GetToken(),
+		TokenType:   "token",
+		Expiry:      token.GetExpiresAt(),
+	}, nil
+}
+
+// appInstallations keeps track of the installations of the app and of the clients
+// authenticated with their installation tokens.
+type appInstallations struct {
+	appClient *github.Client
+	endpoint  Endpoint
+	logger    log.FieldLogger
+
+	mu            sync.Mutex
+	installations map[string]int64
+	clients       map[int64]*github.Client
+	tokens        map[int64]oauth2.TokenSource
+}
+
+// client returns a client authenticated as the installation of the app for the
+// given organization or user, looking it up if the installation is not known yet.
+func (a *appInstallations) client(org string) (*github.Client, error) {
+	a.mu.Lock()
+	id, ok := a.installations[org]
+	a.mu.Unlock()
+
+	if !ok {
+		installation, resp, err := a.appClient.Apps.FindOrganizationInstallation(apiContext("FindOrganizationInstallation", ""), org)
+		if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+			installation, _, err = a.appClient.Apps.FindUserInstallation(apiContext("FindUserInstallation", ""), org)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find the app installation for %s", org)
+		}
+
+		id = installation.GetID()
+		a.set(org, id)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	client, ok := a.clients[id]
+	if !ok {
+		ts := oauth2.ReuseTokenSource(nil, &installationTokenSource{appClient: a.appClient, installationID: id})
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: newMetricsTransport()})
+		var err error
+		client, err = a.endpoint.newClient(oauth2.NewClient(ctx, ts))
+		if err != nil {
+			return nil, err
+		}
+		a.clients[id] = client
+		a.tokens[id] = ts
+	}
+
+	return client, nil
+}
+
+// tokenSource returns the source of the installation tokens for the given
+// organization or user.
+func (a *appInstallations) tokenSource(org string) (oauth2.TokenSource, error) {
+	if _, err := a.client(org); err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ts, ok := a.tokens[a.installations[org]]
+	if !ok {
+		return nil, errors.Errorf("no app installation for %s", org)
+	}
+	return ts, nil
+}
+
+// knownClients returns the clients of the installations already used, by organization.
+func (a *appInstallations) knownClients() map[string]*github.Client {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	clients := make(map[string]*github.Client)
+	for org, id := range a.installations {
+		if client, ok := a.clients[id]; ok {
+			clients[org] = client
+		}
+	}
+	return clients
+}
+
+func (a *appInstallations) set(org string, id int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.installations[org] != id {
+		a.logger.WithFields(log.Fields{
+			"org":          org,
+			"installation": id,
+		}).Info("Registering GitHub App installation")
+	}
+	a.installations[org] = id
+}
+
+func (a *appInstallations) remove(org string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if id, ok := a.installations[org]; ok {
+		a.logger.WithFields(log.Fields{
+			"org":          org,
+			"installation": id,
+		}).Info("Removing GitHub App installation")
+		delete(a.installations, org)
+		delete(a.clients, id)
+		delete(a.tokens, id)
+	}
+}
+
+// NewGitHubAppConfig creates a new GitHub client authenticated as a GitHub App, using
+// the installation tokens of each organization to interact with its repositories.
+func NewGitHubAppConfig(appID int64, privateKey []byte, endpoint Endpoint, gitHubSecrets []string, allowSHA1 bool, logger log.FieldLogger) (*GHClient, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the GitHub App private key")
+	}
+
+	appClient, err := endpoint.newClient(&http.Client{
+		Transport: &appTransport{
+			appID: appID,
+			key:   key,
+			base:  newMetricsTransport(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GHClient{
+		GitHubClient:  appClient,
+		GitHubSecrets: gitHubSecrets,
+		AllowSHA1:     allowSHA1,
+		logger:        logger,
+		endpoint:      endpoint,
+		app: &appInstallations{
+			appClient:     appClient,
+			endpoint:      endpoint,
+			logger:        logger,
+			installations: make(map[string]int64),
+			clients:       make(map[int64]*github.Client),
+			tokens:        make(map[int64]oauth2.TokenSource),
+		},
+	}, nil
+}
+
+// SetInstallation records the installation of the app for an organization or user,
+// as found in the webhook payloads. It does nothing when not running as an app.
+func (g *GHClient) SetInstallation(org string, installationID int64) {
+	if g.app == nil || org == "" || installationID == 0 {
+		return
+	}
+
+	g.app.set(org, installationID)
+}
+
+// RemoveInstallation forgets the installation of the app for an organization or user.
+func (g *GHClient) RemoveInstallation(org string) {
+	if g.app == nil {
+		return
+	}
+
+	g.app.remove(org)
+}
+
+// Token returns a token to authenticate the git operations on the repositories of an
+// organization.
+func (g *GHClient) Token(org string) (string, error) {
+	ts := g.token
+	if g.app != nil {
+		var err error
+		ts, err = g.app.tokenSource(org)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	token, err := ts.Token()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get the GitHub token")
+	}
+	return token.AccessToken, nil
+}
+
+// client returns the client to use to interact with the repositories of an organization.
+func (g *GHClient) client(org string) (*github.Client, error) {
+	if g.app == nil {
+		return g.GitHubClient, nil
+	}
+
+	return g.app.client(org)
+}
@@ -4,8 +4,14 @@ import (
 	"context"
 	"crypto/hmac"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -14,51 +20,141 @@ import (
 	"golang.org/x/oauth2"
 )
 
+// Endpoint is the GitHub instance the client talks to. The zero value is github.com.
+type Endpoint struct {
+	// APIURL is the API URL of a GitHub Enterprise Server instance, like
+	// https://github.example.com/api/v3/.
+	APIURL string
+	// UploadURL is the upload URL of the instance. The API URL is used when empty.
+	UploadURL string
+}
+
+// newClient creates a client of the instance using the given HTTP client.
+func (e Endpoint) newClient(httpClient *http.Client) (*github.Client, error) {
+	if e.APIURL == "" {
+		return github.NewClient(httpClient), nil
+	}
+
+	uploadURL := e.UploadURL
+	if uploadURL == "" {
+		uploadURL = e.APIURL
+	}
+	client, err := github.NewEnterpriseClient(e.APIURL, uploadURL, httpClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid GitHub Enterprise URLs")
+	}
+	return client, nil
+}
+
+// WebURL returns the URL of the web and git server of the instance.
+func (e Endpoint) WebURL() string {
+	if e.APIURL == "" {
+		return "https://github.com"
+	}
+
+	u, err := url.Parse(e.APIURL)
+	if err != nil {
+		return e.APIURL
+	}
+	return u.Scheme + "://" + u.Host
+}
+
 // GHClient set the configuration needed.
 type GHClient struct {
 	GitHubClient *github.Client
-	GitHubSecret string
-	logger       log.FieldLogger
+	// GitHubSecrets are the webhook secrets currently accepted. Having more than one
+	// allows rotating the secret without downtime.
+	GitHubSecrets []string
+	// AllowSHA1 allows validating the deprecated X-Hub-Signature header.
+	AllowSHA1 bool
+	logger    log.FieldLogger
+
+	endpoint Endpoint
+	// token is the token of the client when not running as a GitHub App.
+	token oauth2.TokenSource
+	// app is set when running as a GitHub App.
+	app *appInstallations
 }
 
-// NewGithubClient creates a new GitHub client.
-func NewGithubClient(token string) *github.Client {
+// NewGithubClient creates a new GitHub client of the instance.
+func NewGithubClient(token string, endpoint Endpoint) (*github.Client, error) {
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	tc := oauth2.NewClient(context.Background(), ts)
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: newMetricsTransport()})
+	tc := oauth2.NewClient(ctx, ts)
 
-	return github.NewClient(tc)
+	return endpoint.newClient(tc)
 }
 
 // NewGitHubConfig creates a new KopsProvisioner.
-func NewGitHubConfig(gitHubToken, gitHubSecret string, logger log.FieldLogger) *GHClient {
-	return &GHClient{
-		GitHubClient: NewGithubClient(gitHubToken),
-		GitHubSecret: gitHubSecret,
-		logger:       logger,
+func NewGitHubConfig(gitHubToken string, endpoint Endpoint, gitHubSecrets []string, allowSHA1 bool, logger log.FieldLogger) (*GHClient, error) {
+	client, err := NewGithubClient(gitHubToken, endpoint)
+	if err != nil {
+		return nil, err
 	}
+
+	return &GHClient{
+		GitHubClient:  client,
+		GitHubSecrets: gitHubSecrets,
+		AllowSHA1:     allowSHA1,
+		logger:        logger,
+		endpoint:      endpoint,
+		token:         oauth2.StaticTokenSource(&oauth2.Token{AccessToken: gitHubToken}),
+	}, nil
+}
+
+// WebURL returns the URL of the web and git server of the GitHub instance.
+func (g *GHClient) WebURL() string {
+	return g.endpoint.WebURL()
 }
 
-// ValidateSignature validate the incoming github event.
+// ValidateSignature validate the incoming github event. The received hash is the
+// signature header split in the algorithm and the hex encoded digest.
 func (g *GHClient) ValidateSignature(receivedHash []string, bodyBuffer []byte) error {
-	hash := hmac.New(sha1.New, []byte(g.GitHubSecret))
-	if _, err := hash.Write(bodyBuffer); err != nil {
-		msg := fmt.Sprintf("Cannot compute the HMAC for request: %s\n", err)
-		return errors.New(msg)
+	if len(receivedHash) != 2 {
+		return errors.New("Malformed webhook signature")
 	}
 
-	expectedHash := hex.EncodeToString(hash.Sum(nil))
-	if receivedHash[1] != expectedHash {
-		msg := fmt.Sprintf("Expected Hash does not match the received hash: %s\n", expectedHash)
-		return errors.New(msg)
+	var hashFunc func() hash.Hash
+	switch receivedHash[0] {
+	case "sha256":
+		hashFunc = sha256.New
+	case "sha1":
+		if !g.AllowSHA1 {
+			return errors.New("SHA1 webhook signatures are not allowed")
+		}
+		hashFunc = sha1.New
+	default:
+		return errors.Errorf("Unsupported webhook signature algorithm: %s", receivedHash[0])
 	}
 
-	return nil
+	received, err := hex.DecodeString(receivedHash[1])
+	if err != nil {
+		return errors.Wrap(err, "Malformed webhook signature")
+	}
+
+	for _, secret := range g.GitHubSecrets {
+		mac := hmac.New(hashFunc, []byte(secret))
+		if _, err := mac.Write(bodyBuffer); err != nil {
+			return errors.Wrap(err, "Cannot compute the HMAC for request")
+		}
+
+		if hmac.Equal(received, mac.Sum(nil)) {
+			return nil
+		}
+	}
+
+	return errors.New("Received hash does not match any of the configured secrets")
 }
 
 // CreateComment sends a GitHub Comment to a specific issue/pull request.
 func (g *GHClient) CreateComment(org, repo string, number int, comment string) error {
 	g.logger.WithField("comment", comment).Debug("Sending GitHub comment")
-	_, _, err := g.GitHubClient.Issues.CreateComment(context.Background(), org, repo, number, &github.IssueComment{Body: &comment})
+	client, err := g.client(org)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Issues.CreateComment(apiContext("CreateComment", org), org, repo, number, &github.IssueComment{Body: &comment})
 	if err != nil {
 		return errors.Wrap(err, "Failed to send GitHub comment")
 	}
@@ -69,7 +165,12 @@ func (g *GHClient) CreateComment(org, repo string, number int, comment string) e
 // CreateLabel creates a GitHub label to a specific repository if it doesn't exist.
 func (g *GHClient) CreateLabel(org, repo string, label github.Label) error {
 	g.logger.WithField("labels", label).Debug("Creating GitHub label")
-	_, _, err := g.GitHubClient.Issues.CreateLabel(context.Background(), org, repo, &label)
+	client, err := g.client(org)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Issues.CreateLabel(apiContext("CreateLabel", org), org, repo, &label)
 	if err != nil {
 		return errors.Wrap(err, "Failed to create GitHub label")
 	}
@@ -80,7 +181,12 @@ func (g *GHClient) CreateLabel(org, repo string, label github.Label) error {
 // AddLabels adds a GitHub label to a specific issue/pull request.
 func (g *GHClient) AddLabels(org, repo string, number int, labels []string) error {
 	g.logger.WithField("labels", labels).Debug("Setting GitHub label")
-	_, _, err := g.GitHubClient.Issues.AddLabelsToIssue(context.Background(), org, repo, number, labels)
+	client, err := g.client(org)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Issues.AddLabelsToIssue(apiContext("AddLabels", org), org, repo, number, labels)
 	if err != nil {
 		return errors.Wrap(err, "Failed to set GitHub labels")
 	}
@@ -91,7 +197,12 @@ func (g *GHClient) AddLabels(org, repo string, number int, labels []string) erro
 // RemoveLabel remove a GitHub label from a specific issue/pull request.
 func (g *GHClient) RemoveLabel(org, repo string, number int, label string) error {
 	g.logger.WithField("label", label).Debug("Removing GitHub label")
-	_, err := g.GitHubClient.Issues.RemoveLabelForIssue(context.Background(), org, repo, number, label)
+	client, err := g.client(org)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Issues.RemoveLabelForIssue(apiContext("RemoveLabel", org), org, repo, number, label)
 	if err != nil {
 		return errors.Wrap(err, "Failed to set GitHub labels")
 	}
@@ -106,11 +217,12 @@ func (g *GHClient) GetComments(org, repo string, number int) ([]*github.IssueCom
 		"org":       org,
 		"repo_name": repo,
 	}).Debug("Getting GitHub comment")
-	comments, _, err := g.GitHubClient.Issues.ListComments(context.Background(), org, repo, number, nil)
+	client, err := g.client(org)
 	if err != nil {
-		return nil, errors.Wrap(err, "Failed to set GitHub labels")
+		return nil, err
 	}
-	return comments, nil
+
+	return listComments(client, "GetComments", org, repo, number)
 }
 
 // GetIssueLabels get all the labels for a specific issue/pull request.
@@ -121,14 +233,19 @@ func (g *GHClient) GetIssueLabels(org, repo string, number int) ([]*github.Label
 		"repo_name": repo,
 	}).Debug("Getting GitHub issue label")
 
-	labels, _, err := g.GitHubClient.Issues.ListLabelsByIssue(context.Background(), org, repo, number, nil)
+	client, err := g.client(org)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, _, err := client.Issues.ListLabelsByIssue(apiContext("GetIssueLabels", org), org, repo, number, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to set GitHub labels")
 	}
 	return labels, nil
 }
 
-// ListIssueComments get all the comment for a specific issue/pull request.
+// ListIssueComments get all the comments for a specific issue/pull request.
 func (g *GHClient) ListIssueComments(org, repo string, number int) ([]*github.IssueComment, error) {
 	g.logger.WithFields(log.Fields{
 		"number":    number,
@@ -136,11 +253,40 @@ func (g *GHClient) ListIssueComments(org, repo string, number int) ([]*github.Is
 		"repo_name": repo,
 	}).Debug("Getting GitHub issue label")
 
-	comments, _, err := g.GitHubClient.Issues.ListComments(context.Background(), org, repo, number, nil)
+	client, err := g.client(org)
 	if err != nil {
-		return nil, errors.Wrap(err, "Failed to set GitHub labels")
+		return nil, err
 	}
-	return comments, nil
+
+	return listComments(client, "ListIssueComments", org, repo, number)
+}
+
+// listComments returns all the comments of the issue, going through all the pages.
+func listComments(client *github.Client, method, org, repo string, number int) ([]*github.IssueComment, error) {
+	var allComments []*github.IssueComment
+
+	opt := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	for {
+		comments, resp, err := client.Issues.ListComments(apiContext(method, org), org, repo, number, opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to list the comments")
+		}
+
+		allComments = append(allComments, comments...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return allComments, nil
 }
 
 // IsMember check if a user is member of the org
@@ -155,7 +301,16 @@ func (g *GHClient) IsMember(org, user string) (bool, error) {
 		return true, nil
 	}
 
-	member, resp, err := g.GitHubClient.Organizations.GetOrgMembership(context.Background(), user, org)
+	client, err := g.client(org)
+	if err != nil {
+		return false, err
+	}
+
+	member, resp, err := client.Organizations.GetOrgMembership(apiContext("IsMember", org), user, org)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		// Users who are not members of the org are reported as not found.
+		return false, nil
+	}
 	if err != nil {
 		return false, err
 	}
@@ -190,7 +345,12 @@ func (g *GHClient) SetStatus(org, repo, sha, state, message string) error {
 		TargetURL:   github.String(""),
 	}
 
-	_, _, err := g.GitHubClient.Repositories.CreateStatus(context.Background(), org, repo, sha, mergeStatus)
+	client, err := g.client(org)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Repositories.CreateStatus(apiContext("SetStatus", org), org, repo, sha, mergeStatus)
 	if err != nil {
 		return errors.Wrap(err, "Unable to create the github status for for PR")
 	}
@@ -206,7 +366,12 @@ func (g *GHClient) GetPullRequest(org, repo string, number int) (*github.PullReq
 		"number": number,
 	}).Debug("Getting Pull Request")
 
-	pr, _, err := g.GitHubClient.PullRequests.Get(context.Background(), org, repo, number)
+	client, err := g.client(org)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, _, err := client.PullRequests.Get(apiContext("GetPullRequest", org), org, repo, number)
 	if err != nil {
 		return nil, errors.Wrap(err, "Unable to get the pull request")
 	}
@@ -214,6 +379,323 @@ func (g *GHClient) GetPullRequest(org, repo string, number int) (*github.PullReq
 	return pr, nil
 }
 
+// ListOpenIssues returns the open issues and pull requests of a repository.
+func (g *GHClient) ListOpenIssues(org, repo string) ([]*github.Issue, error) {
+	g.logger.WithFields(log.Fields{
+		"org":  org,
+		"repo": repo,
+	}).Debug("Listing open Issues")
+
+	client, err := g.client(org)
+	if err != nil {
+		return nil, err
+	}
+
+	var allIssues []*github.Issue
+
+	opt := &github.IssueListByRepoOptions{
+		State: "open",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	for {
+		issues, resp, err := client.Issues.ListByRepo(apiContext("ListOpenIssues", org), org, repo, opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to list the issues")
+		}
+
+		allIssues = append(allIssues, issues...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return allIssues, nil
+}
+
+// CloseIssue closes an issue or a pull request.
+func (g *GHClient) CloseIssue(org, repo string, number int) error {
+	g.logger.WithFields(log.Fields{
+		"org":    org,
+		"repo":   repo,
+		"number": number,
+	}).Debug("Closing Issue")
+
+	client, err := g.client(org)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Issues.Edit(apiContext("CloseIssue", org), org, repo, number, &github.IssueRequest{State: github.String("closed")})
+	if err != nil {
+		return errors.Wrap(err, "Unable to close the issue")
+	}
+
+	return nil
+}
+
+// CountMergedPullRequests returns the number of pull requests of the author merged in
+// the repository.
+func (g *GHClient) CountMergedPullRequests(org, repo, author string) (int, error) {
+	g.logger.WithFields(log.Fields{
+		"org":    org,
+		"repo":   repo,
+		"author": author,
+	}).Debug("Counting merged Pull Requests")
+
+	client, err := g.client(org)
+	if err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf("repo:%s/%s is:pr is:merged author:%s", org, repo, author)
+	result, _, err := client.Search.Issues(apiContext("CountMergedPullRequests", org), query, &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 1}})
+	if err != nil {
+		return 0, errors.Wrap(err, "Unable to search the merged pull requests")
+	}
+
+	return result.GetTotal(), nil
+}
+
+// ListPullRequests returns the open pull requests targeting the base branch.
+func (g *GHClient) ListPullRequests(org, repo, base string) ([]*github.PullRequest, error) {
+	g.logger.WithFields(log.Fields{
+		"org":  org,
+		"repo": repo,
+		"base": base,
+	}).Debug("Listing Pull Requests")
+
+	client, err := g.client(org)
+	if err != nil {
+		return nil, err
+	}
+
+	var allPRs []*github.PullRequest
+
+	opt := &github.PullRequestListOptions{
+		State: "open",
+		Base:  base,
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	for {
+		prs, resp, err := client.PullRequests.List(apiContext("ListPullRequests", org), org, repo, opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to list the pull requests")
+		}
+
+		allPRs = append(allPRs, prs...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return allPRs, nil
+}
+
+// CompareCommits returns the commits reachable from head and not from base.
+func (g *GHClient) CompareCommits(org, repo, base, head string) ([]*github.RepositoryCommit, error) {
+	g.logger.WithFields(log.Fields{
+		"org":  org,
+		"repo": repo,
+		"base": base,
+		"head": head,
+	}).Debug("Comparing commits")
+
+	client, err := g.client(org)
+	if err != nil {
+		return nil, err
+	}
+
+	var allCommits []*github.RepositoryCommit
+
+	// The compare endpoint is paginated, which the CompareCommits method of the
+	// library doesn't support.
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("repos/%s/%s/compare/%s...%s?per_page=100&page=%d", org, repo, url.PathEscape(base), url.PathEscape(head), page)
+		req, err := client.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to create the compare request")
+		}
+
+		comparison := new(github.CommitsComparison)
+		if _, err := client.Do(apiContext("CompareCommits", org), req, comparison); err != nil {
+			return nil, errors.Wrap(err, "Unable to compare the commits")
+		}
+
+		allCommits = append(allCommits, comparison.Commits...)
+
+		if len(comparison.Commits) == 0 || len(allCommits) >= comparison.GetTotalCommits() {
+			break
+		}
+	}
+
+	return allCommits, nil
+}
+
+// ListPullRequestsWithCommit returns the pull requests containing the commit.
+func (g *GHClient) ListPullRequestsWithCommit(org, repo, sha string) ([]*github.PullRequest, error) {
+	g.logger.WithFields(log.Fields{
+		"org":  org,
+		"repo": repo,
+		"sha":  sha,
+	}).Debug("Listing Pull Requests with commit")
+
+	client, err := g.client(org)
+	if err != nil {
+		return nil, err
+	}
+
+	prs, _, err := client.PullRequests.ListPullRequestsWithCommit(apiContext("ListPullRequestsWithCommit", org), org, repo, sha, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to list the pull requests with the commit")
+	}
+
+	return prs, nil
+}
+
+// CreatePullRequest opens a pull request merging the head branch into the base branch.
+// The head is prefixed with the owner of the fork, like owner:branch, when it's not
+// in the repository.
+func (g *GHClient) CreatePullRequest(org, repo, title, head, base, body string) (*github.PullRequest, error) {
+	g.logger.WithFields(log.Fields{
+		"org":  org,
+		"repo": repo,
+		"head": head,
+		"base": base,
+	}).Debug("Creating Pull Request")
+
+	client, err := g.client(org)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, _, err := client.PullRequests.Create(apiContext("CreatePullRequest", org), org, repo, &github.NewPullRequest{
+		Title: github.String(title),
+		Head:  github.String(head),
+		Base:  github.String(base),
+		Body:  github.String(body),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to create the pull request")
+	}
+
+	return pr, nil
+}
+
+// EnsureFork forks the repository to the owner if it's not forked yet, and waits for
+// the fork to be created. The owner is either the authenticated user or an organization.
+func (g *GHClient) EnsureFork(org, repo, owner string) error {
+	g.logger.WithFields(log.Fields{
+		"org":   org,
+		"repo":  repo,
+		"owner": owner,
+	}).Debug("Ensuring fork")
+
+	client, err := g.client(org)
+	if err != nil {
+		return err
+	}
+
+	_, resp, err := client.Repositories.Get(apiContext("GetRepository", org), owner, repo)
+	if err == nil {
+		return nil
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return errors.Wrap(err, "Unable to get the fork")
+	}
+
+	opt := &github.RepositoryCreateForkOptions{}
+	user, _, err := client.Users.Get(apiContext("GetUser", org), "")
+	if err != nil || !strings.EqualFold(user.GetLogin(), owner) {
+		opt.Organization = owner
+	}
+
+	_, _, err = client.Repositories.CreateFork(apiContext("CreateFork", org), org, repo, opt)
+	if _, accepted := err.(*github.AcceptedError); err != nil && !accepted {
+		return errors.Wrap(err, "Unable to create the fork")
+	}
+
+	// The fork is created asynchronously.
+	for attempt := 0; attempt < 10; attempt++ {
+		if _, _, err = client.Repositories.Get(apiContext("GetRepository", org), owner, repo); err == nil {
+			return nil
+		}
+		time.Sleep(3 * time.Second)
+	}
+
+	return errors.Wrap(err, "Unable to get the created fork")
+}
+
+// ListPullRequestFiles returns the files changed by a pull request.
+func (g *GHClient) ListPullRequestFiles(org, repo string, number int) ([]*github.CommitFile, error) {
+	g.logger.WithFields(log.Fields{
+		"org":    org,
+		"repo":   repo,
+		"number": number,
+	}).Debug("Listing Pull Request files")
+
+	client, err := g.client(org)
+	if err != nil {
+		return nil, err
+	}
+
+	var allFiles []*github.CommitFile
+
+	opt := &github.ListOptions{
+		PerPage: 100,
+	}
+
+	for {
+		files, resp, err := client.PullRequests.ListFiles(apiContext("ListPullRequestFiles", org), org, repo, number, opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to list the pull request files")
+		}
+
+		allFiles = append(allFiles, files...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return allFiles, nil
+}
+
+// RequestReviewers requests the review of a pull request to the given users.
+func (g *GHClient) RequestReviewers(org, repo string, number int, reviewers []string) error {
+	g.logger.WithFields(log.Fields{
+		"org":       org,
+		"repo":      repo,
+		"number":    number,
+		"reviewers": reviewers,
+	}).Debug("Requesting reviewers")
+
+	client, err := g.client(org)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.PullRequests.RequestReviewers(apiContext("RequestReviewers", org), org, repo, number, github.ReviewersRequest{Reviewers: reviewers})
+	if err != nil {
+		return errors.Wrap(err, "Unable to request the reviewers")
+	}
+
+	return nil
+}
+
 // ListRepoLabels list all labels for a repo
 func (g *GHClient) ListRepoLabels(org, repo string) ([]*github.Label, error) {
 	g.logger.WithFields(log.Fields{
@@ -221,6 +703,11 @@ func (g *GHClient) ListRepoLabels(org, repo string) ([]*github.Label, error) {
 		"repo": repo,
 	}).Debug("Getting Repo labels")
 
+	client, err := g.client(org)
+	if err != nil {
+		return nil, err
+	}
+
 	var allLabels []*github.Label
 
 	opt := &github.ListOptions{
@@ -228,7 +715,7 @@ func (g *GHClient) ListRepoLabels(org, repo string) ([]*github.Label, error) {
 	}
 
 	for {
-		labels, resp, err := g.GitHubClient.Issues.ListLabels(context.Background(), org, repo, opt)
+		labels, resp, err := client.Issues.ListLabels(apiContext("ListRepoLabels", org), org, repo, opt)
 		if err != nil {
 			return nil, errors.Wrap(err, "Unable to get the pull request")
 		}
@@ -245,3 +732,229 @@ func (g *GHClient) ListRepoLabels(org, repo string) ([]*github.Label, error) {
 	return allLabels, nil
 
 }
+
+// EditLabel updates the name, color and description of a label of a repository.
+func (g *GHClient) EditLabel(org, repo, name string, label github.Label) error {
+	g.logger.WithFields(log.Fields{
+		"org":   org,
+		"repo":  repo,
+		"label": name,
+	}).Debug("Editing GitHub label")
+
+	client, err := g.client(org)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Issues.EditLabel(apiContext("EditLabel", org), org, repo, name, &label)
+	if err != nil {
+		return errors.Wrap(err, "Failed to edit GitHub label")
+	}
+
+	return nil
+}
+
+// DeleteLabel deletes a label of a repository, removing it from all the issues and
+// pull requests.
+func (g *GHClient) DeleteLabel(org, repo, name string) error {
+	g.logger.WithFields(log.Fields{
+		"org":   org,
+		"repo":  repo,
+		"label": name,
+	}).Debug("Deleting GitHub label")
+
+	client, err := g.client(org)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Issues.DeleteLabel(apiContext("DeleteLabel", org), org, repo, name)
+	if err != nil {
+		return errors.Wrap(err, "Failed to delete GitHub label")
+	}
+
+	return nil
+}
+
+// ListOrgRepos returns the repositories of an organization.
+func (g *GHClient) ListOrgRepos(org string) ([]*github.Repository, error) {
+	g.logger.WithField("org", org).Debug("Listing org Repos")
+
+	client, err := g.client(org)
+	if err != nil {
+		return nil, err
+	}
+
+	var allRepos []*github.Repository
+
+	opt := &github.RepositoryListByOrgOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	for {
+		repos, resp, err := client.Repositories.ListByOrg(apiContext("ListOrgRepos", org), org, opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to list the repositories")
+		}
+
+		allRepos = append(allRepos, repos...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return allRepos, nil
+}
+
+// GetFileContent gets the content of a file from a repository at the given ref. When
+// the ref is empty the default branch is used. It returns nil if the file doesn't exist.
+func (g *GHClient) GetFileContent(org, repo, path, ref string) ([]byte, error) {
+	g.logger.WithFields(log.Fields{
+		"org":  org,
+		"repo": repo,
+		"path": path,
+		"ref":  ref,
+	}).Debug("Getting file content")
+
+	var opts *github.RepositoryContentGetOptions
+	if ref != "" {
+		opts = &github.RepositoryContentGetOptions{Ref: ref}
+	}
+
+	client, err := g.client(org)
+	if err != nil {
+		return nil, err
+	}
+
+	file, _, resp, err := client.Repositories.GetContents(apiContext("GetFileContent", org), org, repo, path, opts)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "Unable to get the file %s", path)
+	}
+	if file == nil {
+		return nil, nil
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to decode the file %s", path)
+	}
+
+	return []byte(content), nil
+}
+
+// GetRateLimit checks the GitHub credentials and returns the core API rate limit.
+// When running as a GitHub App, the app is authenticated and the lowest rate limit of
+// the known installations is taken from the headers of their latest responses, so the
+// cost doesn't grow with the installations. It's nil if no installation was used yet.
+func (g *GHClient) GetRateLimit() (*github.Rate, error) {
+	g.logger.Debug("Getting rate limit")
+
+	if g.app == nil {
+		limits, _, err := g.GitHubClient.RateLimits(apiContext("GetRateLimit", ""))
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to get the rate limit")
+		}
+		return limits.GetCore(), nil
+	}
+
+	if _, _, err := g.GitHubClient.Apps.Get(apiContext("GetRateLimit", ""), ""); err != nil {
+		return nil, errors.Wrap(err, "Unable to authenticate as the GitHub App")
+	}
+
+	var orgs []string
+	for org := range g.app.knownClients() {
+		orgs = append(orgs, org)
+	}
+
+	return observedRates.lowest(orgs, time.Now()), nil
+}
+
+// SupportsChecks returns whether the client can publish check runs, which are
+// reserved to GitHub Apps.
+func (g *GHClient) SupportsChecks() bool {
+	return g.app != nil
+}
+
+// CreateCheckRun creates a check run for a commit.
+func (g *GHClient) CreateCheckRun(org, repo string, checkRun github.CreateCheckRunOptions) error {
+	g.logger.WithFields(log.Fields{
+		"org":    org,
+		"repo":   repo,
+		"sha":    checkRun.HeadSHA,
+		"name":   checkRun.Name,
+		"status": checkRun.GetStatus(),
+	}).Debug("Creating check run")
+
+	client, err := g.client(org)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Checks.CreateCheckRun(apiContext("CreateCheckRun", org), org, repo, checkRun)
+	if err != nil {
+		return errors.Wrap(err, "Unable to create the check run")
+	}
+
+	return nil
+}
+
+// GetCheckRun returns the latest check run with the name for a commit, or nil if there
+// is none.
+func (g *GHClient) GetCheckRun(org, repo, sha, name string) (*github.CheckRun, error) {
+	g.logger.WithFields(log.Fields{
+		"org":  org,
+		"repo": repo,
+		"sha":  sha,
+		"name": name,
+	}).Debug("Getting check run")
+
+	client, err := g.client(org)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &github.ListCheckRunsOptions{
+		CheckName: github.String(name),
+		Filter:    github.String("latest"),
+	}
+	result, _, err := client.Checks.ListCheckRunsForRef(apiContext("GetCheckRun", org), org, repo, sha, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to list the check runs")
+	}
+	if len(result.CheckRuns) == 0 {
+		return nil, nil
+	}
+
+	return result.CheckRuns[0], nil
+}
+
+// UpdateCheckRun updates an existing check run.
+func (g *GHClient) UpdateCheckRun(org, repo string, id int64, checkRun github.UpdateCheckRunOptions) error {
+	g.logger.WithFields(log.Fields{
+		"org":        org,
+		"repo":       repo,
+		"check_run":  id,
+		"name":       checkRun.Name,
+		"conclusion": checkRun.GetConclusion(),
+	}).Debug("Updating check run")
+
+	client, err := g.client(org)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Checks.UpdateCheckRun(apiContext("UpdateCheckRun", org), org, repo, id, checkRun)
+	if err != nil {
+		return errors.Wrap(err, "Unable to update the check run")
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func sign(hashFunc func() hash.Hash, secret string, body []byte) string {
+	mac := hmac.New(hashFunc, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestValidateSignature(t *testing.T) {
+	body := []byte(`{"action":"opened"}`)
+	client, err := NewGitHubConfig("token", Endpoint{}, []string{"old", "new"}, false, log.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name         string
+		receivedHash []string
+		allowSHA1    bool
+		valid        bool
+	}{
+		{"sha256 with the current secret", []string{"sha256", sign(sha256.New, "new", body)}, false, true},
+		{"sha256 with the previous secret", []string{"sha256", sign(sha256.New, "old", body)}, false, true},
+		{"sha256 with an unknown secret", []string{"sha256", sign(sha256.New, "other", body)}, false, false},
+		{"sha1 not allowed", []string{"sha1", sign(sha1.New, "new", body)}, false, false},
+		{"sha1 allowed", []string{"sha1", sign(sha1.New, "new", body)}, true, true},
+		{"missing digest", []string{""}, false, false},
+		{"malformed digest", []string{"sha256", "zz"}, false, false},
+		{"unknown algorithm", []string{"md5", "00"}, true, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client.AllowSHA1 = tc.allowSHA1
+			err := client.ValidateSignature(tc.receivedHash, body)
+			if tc.valid && err != nil {
+				t.Fatalf("expected a valid signature, got %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatal("expected an invalid signature")
+			}
+		})
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	client, err := Endpoint{}.newClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.BaseURL.String() != "https://api.github.com/" {
+		t.Fatalf("expected the github.com API, got %s", client.BaseURL)
+	}
+	if web := (Endpoint{}).WebURL(); web != "https://github.com" {
+		t.Fatalf("expected the github.com web URL, got %s", web)
+	}
+
+	enterprise := Endpoint{APIURL: "https://github.example.com/api/v3"}
+	client, err = enterprise.newClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.BaseURL.String() != "https://github.example.com/api/v3/" || client.UploadURL.String() != "https://github.example.com/api/v3/" {
+		t.Fatalf("unexpected GitHub Enterprise URLs %s and %s", client.BaseURL, client.UploadURL)
+	}
+	if web := enterprise.WebURL(); web != "https://github.example.com" {
+		t.Fatalf("expected the GitHub Enterprise web URL, got %s", web)
+	}
+}
+
+func TestObservedRates(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	remaining := map[string]string{"/low": "10", "/high": "4000"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", remaining[r.URL.Path])
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newMetricsTransport()}
+	for path, org := range map[string]string{"/low": "rates-low", "/high": "rates-high"} {
+		req, err := http.NewRequestWithContext(apiContext("Test", org), http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	rate := observedRates.lowest([]string{"rates-low", "rates-high", "rates-unknown"}, time.Now())
+	if rate == nil || rate.Remaining != 10 || !rate.Reset.Time.Equal(reset) {
+		t.Fatalf("expected the lowest rate limit, got %v", rate)
+	}
+	if rate := observedRates.lowest([]string{"rates-low"}, reset.Add(time.Second)); rate != nil {
+		t.Fatalf("expected the reset rate limit to be ignored, got %v", rate)
+	}
+}
+
+func TestListIssueComments(t *testing.T) {
+	// The server returns at most 20 comments per page, like a busy PR needing a few pages.
+	const total, pageSize = 45, 20
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/org/repo/issues/7/comments" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		var comments []map[string]interface{}
+		for id := (page-1)*pageSize + 1; id <= page*pageSize && id <= total; id++ {
+			comments = append(comments, map[string]interface{}{"id": id})
+		}
+		if page*pageSize < total {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=%d>; rel="next"`, server.URL, r.URL.Path, page+1))
+		}
+		json.NewEncoder(w).Encode(comments)
+	}))
+	defer server.Close()
+
+	client, err := NewGitHubConfig("token", Endpoint{APIURL: server.URL + "/api/v3/"}, nil, false, log.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comments, err := client.ListIssueComments("org", "repo", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != total || comments[total-1].GetID() != total {
+		t.Fatalf("expected the %d comments, got %d", total, len(comments))
+	}
+}
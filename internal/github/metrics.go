@@ -0,0 +1,99 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/metrics"
+
+	"github.com/google/go-github/v31/github"
+)
+
+type contextKey int
+
+const (
+	methodKey contextKey = iota
+	orgKey
+)
+
+// apiContext returns the context of a GitHub API call, carrying the client method and
+// the organization used to label the metrics.
+func apiContext(method, org string) context.Context {
+	ctx := context.WithValue(context.Background(), methodKey, method)
+	return context.WithValue(ctx, orgKey, org)
+}
+
+// metricsTransport records the metrics of the requests made to the GitHub API.
+type metricsTransport struct {
+	base http.RoundTripper
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method, _ := req.Context().Value(methodKey).(string)
+	if method == "" {
+		method = "other"
+	}
+	org, _ := req.Context().Value(orgKey).(string)
+
+	metrics.GitHubAPICalls.WithLabelValues(method).Inc()
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		metrics.GitHubAPIFailures.WithLabelValues(method).Inc()
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		metrics.GitHubAPIFailures.WithLabelValues(method).Inc()
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		metrics.GitHubRateLimitRemaining.WithLabelValues(org).Set(float64(remaining))
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil && org != "" {
+			observedRates.set(org, remaining, time.Unix(reset, 0))
+		}
+	}
+
+	return resp, nil
+}
+
+func newMetricsTransport() http.RoundTripper {
+	return &metricsTransport{base: http.DefaultTransport}
+}
+
+// observedRates are the rate limits of the organizations, as reported by the headers
+// of the latest responses, so they can be checked without calling the API.
+var observedRates = &rateTracker{rates: make(map[string]*github.Rate)}
+
+type rateTracker struct {
+	mu    sync.Mutex
+	rates map[string]*github.Rate
+}
+
+func (t *rateTracker) set(org string, remaining int, reset time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rates[org] = &github.Rate{Remaining: remaining, Reset: github.Timestamp{Time: reset}}
+}
+
+// lowest returns the lowest rate limit of the organizations which is not reset yet,
+// or nil if there is none.
+func (t *rateTracker) lowest(orgs []string, now time.Time) *github.Rate {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var lowest *github.Rate
+	for _, org := range orgs {
+		rate, ok := t.rates[org]
+		if !ok || !rate.Reset.After(now) {
+			continue
+		}
+		if lowest == nil || rate.Remaining < lowest.Remaining {
+			lowest = rate
+		}
+	}
+	return lowest
+}
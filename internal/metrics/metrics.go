@@ -0,0 +1,56 @@
+// Package metrics defines the Prometheus metrics exposed by Chewbacca.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "chewbacca"
+
+var (
+	// WebhooksReceived counts the webhooks received, by event type and action.
+	WebhooksReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhooks_received_total",
+		Help:      "The number of webhooks received from GitHub.",
+	}, []string{"event", "action"})
+
+	// PluginDuration observes how long the plugins take to handle an event.
+	PluginDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "plugin_duration_seconds",
+		Help:      "The time taken by the plugins to handle an event.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"plugin"})
+
+	// PluginErrors counts the events the plugins failed to handle.
+	PluginErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "plugin_errors_total",
+		Help:      "The number of events the plugins failed to handle.",
+	}, []string{"plugin"})
+
+	// GitHubAPICalls counts the requests made to the GitHub API, by client method.
+	GitHubAPICalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "github_api_calls_total",
+		Help:      "The number of requests made to the GitHub API.",
+	}, []string{"method"})
+
+	// GitHubAPIFailures counts the requests to the GitHub API which failed or
+	// returned an error status, by client method.
+	GitHubAPIFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "github_api_failures_total",
+		Help:      "The number of requests to the GitHub API which failed or returned an error status.",
+	}, []string{"method"})
+
+	// GitHubRateLimitRemaining is the remaining rate limit quota reported by GitHub,
+	// by organization. The organization is empty for the requests made as the app.
+	GitHubRateLimitRemaining = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "github_rate_limit_remaining",
+		Help:      "The remaining GitHub API rate limit quota.",
+	}, []string{"org"})
+)
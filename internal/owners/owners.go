@@ -0,0 +1,218 @@
+// Package owners reads the OWNERS files of a repository and resolves the approvers
+// and reviewers of the files changed by a pull request.
+package owners
+
+import (
+	"math/rand"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/chewbacca/internal/utils"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// FileName is the name of the OWNERS files.
+const FileName = "OWNERS"
+
+// FileGetter describes the interface required to fetch the OWNERS files.
+type FileGetter interface {
+	// GetFileContent returns the content of a file at the given ref, or nil content
+	// if the file doesn't exist.
+	GetFileContent(org, repo, path, ref string) ([]byte, error)
+}
+
+// File is the content of an OWNERS file.
+type File struct {
+	Approvers []string `yaml:"approvers"`
+	Reviewers []string `yaml:"reviewers"`
+	Labels    []string `yaml:"labels"`
+	Options   struct {
+		// NoParentOwners stops the owners of the parent directories from owning the
+		// files of this directory.
+		NoParentOwners bool `yaml:"no_parent_owners"`
+	} `yaml:"options"`
+}
+
+// Owners holds the OWNERS files found for a set of paths, by directory.
+type Owners struct {
+	files map[string]*File
+}
+
+// Load fetches the OWNERS files of the directories containing the given paths and of
+// all their parent directories.
+func Load(getter FileGetter, org, repo, ref string, paths []string) (*Owners, error) {
+	o := &Owners{files: make(map[string]*File)}
+	fetched := sets.New[string]()
+	for _, p := range paths {
+		for _, dir := range parentDirs(p) {
+			if fetched.Has(dir) {
+				continue
+			}
+			fetched.Insert(dir)
+
+			data, err := getter.GetFileContent(org, repo, path.Join(dir, FileName), ref)
+			if err != nil {
+				return nil, err
+			}
+			if data == nil {
+				continue
+			}
+
+			var file File
+			if err := yaml.Unmarshal(data, &file); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse %s", ownersPath(dir))
+			}
+			o.files[dir] = &file
+		}
+	}
+
+	return o, nil
+}
+
+// Empty returns whether no OWNERS file was found.
+func (o *Owners) Empty() bool {
+	return len(o.files) == 0
+}
+
+// Approvers returns the users allowed to approve the changes to the given path.
+func (o *Owners) Approvers(p string) sets.Set[string] {
+	approvers := sets.New[string]()
+	for _, file := range o.chain(p) {
+		for _, approver := range file.Approvers {
+			approvers.Insert(utils.NormLogin(approver))
+		}
+	}
+	return approvers
+}
+
+// Reviewers returns the users who can review the changes to the given path. The
+// approvers are also reviewers.
+func (o *Owners) Reviewers(p string) sets.Set[string] {
+	reviewers := o.Approvers(p)
+	for _, file := range o.chain(p) {
+		for _, reviewer := range file.Reviewers {
+			reviewers.Insert(utils.NormLogin(reviewer))
+		}
+	}
+	return reviewers
+}
+
+// Labels returns the labels to apply to the pull requests changing the given path.
+func (o *Owners) Labels(p string) sets.Set[string] {
+	labels := sets.New[string]()
+	for _, file := range o.chain(p) {
+		labels.Insert(file.Labels...)
+	}
+	return labels
+}
+
+// OwnersFile returns the path of the nearest OWNERS file owning the given path, or
+// an empty string if there is none.
+func (o *Owners) OwnersFile(p string) string {
+	for _, dir := range parentDirs(p) {
+		if _, ok := o.files[dir]; ok {
+			return ownersPath(dir)
+		}
+	}
+	return ""
+}
+
+// Unapproved returns the paths which are not approved by any of the given approvers.
+// The paths without any approver don't need to be approved.
+func (o *Owners) Unapproved(paths []string, approvers sets.Set[string]) []string {
+	var unapproved []string
+	for _, p := range paths {
+		pathApprovers := o.Approvers(p)
+		if pathApprovers.Len() > 0 && !pathApprovers.HasAny(sets.List(approvers)...) {
+			unapproved = append(unapproved, p)
+		}
+	}
+	return unapproved
+}
+
+// SelectReviewers picks up to count reviewers for the given paths, excluding the
+// author. The paths are grouped by their nearest OWNERS file and a random reviewer is
+// picked for each group, starting from the groups with the most paths, so the review
+// load is spread across the owners.
+func (o *Owners) SelectReviewers(paths []string, author string, count int, rnd *rand.Rand) []string {
+	groups := make(map[string][]string)
+	for _, p := range paths {
+		if file := o.OwnersFile(p); file != "" {
+			groups[file] = append(groups[file], p)
+		}
+	}
+
+	var files []string
+	for file := range groups {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if len(groups[files[i]]) != len(groups[files[j]]) {
+			return len(groups[files[i]]) > len(groups[files[j]])
+		}
+		return files[i] < files[j]
+	})
+
+	author = utils.NormLogin(author)
+	selected := sets.New[string]()
+	var reviewers []string
+	for _, file := range files {
+		if len(reviewers) >= count {
+			break
+		}
+
+		candidates := o.Reviewers(groups[file][0])
+		candidates.Delete(author)
+		if candidates.HasAny(sets.List(selected)...) {
+			// The group is already covered by a selected reviewer.
+			continue
+		}
+
+		list := sets.List(candidates)
+		if len(list) == 0 {
+			continue
+		}
+		reviewer := list[rnd.Intn(len(list))]
+		selected.Insert(reviewer)
+		reviewers = append(reviewers, reviewer)
+	}
+
+	return reviewers
+}
+
+// chain returns the OWNERS files owning the given path, from the nearest one up to
+// the root, stopping at the files with no_parent_owners.
+func (o *Owners) chain(p string) []*File {
+	var files []*File
+	for _, dir := range parentDirs(p) {
+		file, ok := o.files[dir]
+		if !ok {
+			continue
+		}
+		files = append(files, file)
+		if file.Options.NoParentOwners {
+			break
+		}
+	}
+	return files
+}
+
+// parentDirs returns the directories containing the path, from the nearest one up
+// to the root, which is represented by an empty string.
+func parentDirs(p string) []string {
+	var dirs []string
+	dir := path.Dir(strings.TrimPrefix(p, "/"))
+	for dir != "." && dir != "/" {
+		dirs = append(dirs, dir)
+		dir = path.Dir(dir)
+	}
+	return append(dirs, "")
+}
+
+func ownersPath(dir string) string {
+	return path.Join(dir, FileName)
+}
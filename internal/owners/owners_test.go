@@ -0,0 +1,95 @@
+package owners_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/mattermost/chewbacca/internal/owners"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type fakeFileGetter map[string]string
+
+func (f fakeFileGetter) GetFileContent(org, repo, path, ref string) ([]byte, error) {
+	content, ok := f[path]
+	if !ok {
+		return nil, nil
+	}
+	return []byte(content), nil
+}
+
+func load(t *testing.T, paths []string) *owners.Owners {
+	getter := fakeFileGetter{
+		"OWNERS": `
+approvers:
+- root
+reviewers:
+- rootreviewer
+`,
+		"server/OWNERS": `
+approvers:
+- Server
+labels:
+- area/server
+`,
+		"webapp/OWNERS": `
+approvers:
+- webapp
+options:
+  no_parent_owners: true
+`,
+	}
+
+	o, err := owners.Load(getter, "org", "repo", "main", paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func TestApprovers(t *testing.T) {
+	paths := []string{"README.md", "server/api/api.go", "webapp/index.js"}
+	o := load(t, paths)
+
+	if approvers := o.Approvers("server/api/api.go"); !approvers.Equal(sets.New("root", "server")) {
+		t.Fatalf("expected the server and root approvers, got %v", sets.List(approvers))
+	}
+	if approvers := o.Approvers("webapp/index.js"); !approvers.Equal(sets.New("webapp")) {
+		t.Fatalf("expected only the webapp approvers, got %v", sets.List(approvers))
+	}
+	if labels := o.Labels("server/api/api.go"); !labels.Equal(sets.New("area/server")) {
+		t.Fatalf("expected the server labels, got %v", sets.List(labels))
+	}
+	if file := o.OwnersFile("server/api/api.go"); file != "server/OWNERS" {
+		t.Fatalf("expected server/OWNERS, got %q", file)
+	}
+
+	unapproved := o.Unapproved(paths, sets.New("server"))
+	if len(unapproved) != 2 || unapproved[0] != "README.md" || unapproved[1] != "webapp/index.js" {
+		t.Fatalf("expected README.md and webapp/index.js to be unapproved, got %v", unapproved)
+	}
+	if unapproved := o.Unapproved(paths, sets.New("root", "webapp")); len(unapproved) != 0 {
+		t.Fatalf("expected all the paths to be approved, got %v", unapproved)
+	}
+}
+
+func TestSelectReviewers(t *testing.T) {
+	paths := []string{"server/a.go", "server/b.go", "webapp/index.js"}
+	o := load(t, paths)
+
+	for seed := int64(0); seed < 10; seed++ {
+		reviewers := o.SelectReviewers(paths, "webapp", 2, rand.New(rand.NewSource(seed)))
+		if len(reviewers) != 1 {
+			t.Fatalf("expected one reviewer since the author is the only webapp owner, got %v", reviewers)
+		}
+		if !sets.New("root", "rootreviewer", "server").Has(reviewers[0]) {
+			t.Fatalf("expected a server reviewer, got %v", reviewers)
+		}
+	}
+
+	reviewers := o.SelectReviewers(paths, "someone", 2, rand.New(rand.NewSource(1)))
+	if len(reviewers) != 2 || reviewers[1] != "webapp" {
+		t.Fatalf("expected a server reviewer and the webapp reviewer, got %v", reviewers)
+	}
+}
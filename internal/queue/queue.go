@@ -0,0 +1,412 @@
+// Package queue implements a persistent queue of webhook events processed by a
+// fixed-size pool of workers.
+package queue
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	pendingBucket = []byte("pending")
+	deadBucket    = []byte("dead")
+
+	// ErrJobNotFound is returned when a job doesn't exist in the queue.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobCorrupted is returned when retrying a dead letter whose record couldn't be
+	// decoded, since it would fail again.
+	ErrJobCorrupted = errors.New("job corrupted")
+)
+
+// Job is a webhook event waiting to be processed.
+type Job struct {
+	ID         uint64 `json:"id"`
+	DeliveryID string `json:"delivery_id"`
+	EventType  string `json:"event_type"`
+	Payload    []byte `json:"payload"`
+	// Plugins restricts the plugins run when retrying the job to the ones that failed.
+	Plugins     []string  `json:"plugins,omitempty"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	// Corrupted is set on the dead letters whose record couldn't be decoded. Their
+	// payload is the raw record.
+	Corrupted bool `json:"corrupted,omitempty"`
+}
+
+// Handler processes a job. Returning an error schedules a retry.
+type Handler func(job *Job) error
+
+// Options configures the queue.
+type Options struct {
+	// Workers is the number of jobs processed concurrently.
+	Workers int
+	// MaxAttempts is the number of attempts before a job is moved to the dead letters.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled on each following attempt.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between two attempts.
+	MaxBackoff time.Duration
+	// PollInterval is how often idle workers look for jobs ready to be retried.
+	PollInterval time.Duration
+}
+
+// Queue is a persistent job queue backed by a BoltDB file.
+type Queue struct {
+	db      *bolt.DB
+	handler Handler
+	options Options
+	logger  log.FieldLogger
+
+	mu       sync.Mutex
+	inFlight map[uint64]bool
+
+	notify chan struct{}
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// New opens or creates the queue stored at path.
+func New(path string, handler Handler, options Options, logger log.FieldLogger) (*Queue, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open the queue database %s", path)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{pendingBucket, deadBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to initialize the queue database")
+	}
+
+	if options.Workers < 1 {
+		options.Workers = 1
+	}
+	if options.MaxAttempts < 1 {
+		options.MaxAttempts = 1
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = time.Second
+	}
+
+	return &Queue{
+		db:       db,
+		handler:  handler,
+		options:  options,
+		logger:   logger,
+		inFlight: make(map[uint64]bool),
+		notify:   make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}, nil
+}
+
+// Start starts the workers.
+func (q *Queue) Start() {
+	for i := 0; i < q.options.Workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+}
+
+// Stop waits for the running jobs to finish, stops the workers and closes the database.
+func (q *Queue) Stop() error {
+	close(q.stop)
+	q.wg.Wait()
+
+	return q.db.Close()
+}
+
+// Enqueue stores a new job to be processed by the workers.
+func (q *Queue) Enqueue(eventType, deliveryID string, payload []byte) error {
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(pendingBucket)
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return putJob(bucket, &Job{
+			ID:          id,
+			DeliveryID:  deliveryID,
+			EventType:   eventType,
+			Payload:     payload,
+			NextAttempt: now,
+			CreatedAt:   now,
+		})
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to enqueue the job")
+	}
+
+	q.wakeUp()
+	return nil
+}
+
+// Depth returns the number of jobs waiting to be processed.
+func (q *Queue) Depth() (int, error) {
+	var depth int
+	err := q.db.View(func(tx *bolt.Tx) error {
+		depth = tx.Bucket(pendingBucket).Stats().KeyN
+		return nil
+	})
+
+	return depth, err
+}
+
+// DeadLetters returns the jobs that failed too many times.
+func (q *Queue) DeadLetters() ([]*Job, error) {
+	var jobs []*Job
+	err := q.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(deadBucket).ForEach(func(_, value []byte) error {
+			var job Job
+			if err := json.Unmarshal(value, &job); err != nil {
+				return err
+			}
+			jobs = append(jobs, &job)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the dead letters")
+	}
+
+	return jobs, nil
+}
+
+// RetryDeadLetter moves a dead letter back to the pending jobs. The corrupted jobs
+// can't be retried.
+func (q *Queue) RetryDeadLetter(id uint64) error {
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		dead := tx.Bucket(deadBucket)
+		value := dead.Get(itob(id))
+		if value == nil {
+			return ErrJobNotFound
+		}
+
+		var job Job
+		if err := json.Unmarshal(value, &job); err != nil {
+			return err
+		}
+		if job.Corrupted || job.EventType == "" {
+			return ErrJobCorrupted
+		}
+		job.Attempts = 0
+		job.NextAttempt = time.Now()
+
+		if err := dead.Delete(itob(id)); err != nil {
+			return err
+		}
+		return putJob(tx.Bucket(pendingBucket), &job)
+	})
+	if err != nil {
+		return err
+	}
+
+	q.wakeUp()
+	return nil
+}
+
+func (q *Queue) wakeUp() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := q.claim()
+		if err != nil {
+			q.logger.WithError(err).Error("failed to claim a job from the queue")
+		}
+
+		if job != nil {
+			q.process(job)
+			continue
+		}
+
+		select {
+		case <-q.stop:
+			return
+		case <-q.notify:
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim returns the oldest job ready to be processed which isn't processed by another
+// worker, or nil if there is none.
+func (q *Queue) claim() (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case <-q.stop:
+		return nil, nil
+	default:
+	}
+
+	var claimed *Job
+	corrupted := make(map[uint64][]byte)
+	now := time.Now()
+	err := q.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(pendingBucket).Cursor()
+		for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
+			id := binary.BigEndian.Uint64(key)
+			if q.inFlight[id] {
+				continue
+			}
+
+			var job Job
+			if err := json.Unmarshal(value, &job); err != nil {
+				q.logger.WithError(err).WithField("job", id).Error("corrupted job in the queue, moving it to the dead letters")
+				corrupted[id] = append([]byte(nil), value...)
+				continue
+			}
+			if job.NextAttempt.After(now) {
+				continue
+			}
+
+			claimed = &job
+			return nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(corrupted) > 0 {
+		if err := q.moveCorrupted(corrupted); err != nil {
+			q.logger.WithError(err).Error("failed to move the corrupted jobs to the dead letters")
+		}
+	}
+	if claimed == nil {
+		return nil, nil
+	}
+
+	q.inFlight[claimed.ID] = true
+	return claimed, nil
+}
+
+// moveCorrupted moves the pending records which can't be decoded to the dead letters,
+// keeping their raw content as payload, so they don't block the queue.
+func (q *Queue) moveCorrupted(records map[uint64][]byte) error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		for id, value := range records {
+			if err := tx.Bucket(pendingBucket).Delete(itob(id)); err != nil {
+				return err
+			}
+			job := &Job{
+				ID:        id,
+				Payload:   value,
+				LastError: "corrupted job",
+				CreatedAt: time.Now(),
+				Corrupted: true,
+			}
+			if err := putJob(tx.Bucket(deadBucket), job); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (q *Queue) process(job *Job) {
+	defer func() {
+		q.mu.Lock()
+		delete(q.inFlight, job.ID)
+		q.mu.Unlock()
+	}()
+
+	logger := q.logger.WithFields(log.Fields{
+		"job":      job.ID,
+		"event":    job.EventType,
+		"delivery": job.DeliveryID,
+		"attempt":  job.Attempts + 1,
+	})
+
+	handlerErr := q.handle(job)
+
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		pending := tx.Bucket(pendingBucket)
+		if handlerErr == nil {
+			return pending.Delete(itob(job.ID))
+		}
+
+		job.Attempts++
+		job.LastError = handlerErr.Error()
+		if job.Attempts >= q.options.MaxAttempts {
+			logger.WithError(handlerErr).Error("job failed too many times, moving it to the dead letters")
+			if err := pending.Delete(itob(job.ID)); err != nil {
+				return err
+			}
+			return putJob(tx.Bucket(deadBucket), job)
+		}
+
+		job.NextAttempt = time.Now().Add(q.backoff(job.Attempts))
+		logger.WithError(handlerErr).WithField("next_attempt", job.NextAttempt).Warn("job failed, scheduling a retry")
+		return putJob(pending, job)
+	})
+	if err != nil {
+		logger.WithError(err).Error("failed to update the job in the queue")
+	}
+}
+
+// handle runs the handler, turning a panic into an error so the job is retried.
+func (q *Queue) handle(job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic while processing the job: %v", r)
+		}
+	}()
+
+	return q.handler(job)
+}
+
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.options.Backoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if q.options.MaxBackoff > 0 && delay >= q.options.MaxBackoff {
+			return q.options.MaxBackoff
+		}
+	}
+
+	return delay
+}
+
+func putJob(bucket *bolt.Bucket, job *Job) error {
+	value, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put(itob(job.ID), value)
+}
+
+func itob(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
@@ -0,0 +1,160 @@
+package queue_test
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/chewbacca/internal/queue"
+
+	log "github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+)
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the queue")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	handler := func(job *queue.Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[job.DeliveryID]++
+		if job.DeliveryID == "failing" {
+			return errors.New("failure")
+		}
+		return nil
+	}
+
+	path := filepath.Join(t.TempDir(), "queue.db")
+	q, err := queue.New(path, handler, queue.Options{
+		Workers:      2,
+		MaxAttempts:  3,
+		Backoff:      time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+	}, log.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Start()
+
+	if err = q.Enqueue("pull_request", "working", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if err = q.Enqueue("pull_request", "failing", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool {
+		depth, err := q.Depth()
+		return err == nil && depth == 0
+	})
+
+	deadLetters, err := q.DeadLetters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deadLetters) != 1 || deadLetters[0].DeliveryID != "failing" || deadLetters[0].LastError != "failure" {
+		t.Fatalf("expected the failing job in the dead letters, got %v", deadLetters)
+	}
+
+	mu.Lock()
+	if attempts["working"] != 1 || attempts["failing"] != 3 {
+		t.Fatalf("unexpected attempts: %v", attempts)
+	}
+	mu.Unlock()
+
+	if err = q.RetryDeadLetter(deadLetters[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return attempts["failing"] == 6
+	})
+
+	if err = q.RetryDeadLetter(12345); err != queue.ErrJobNotFound {
+		t.Fatalf("expected ErrJobNotFound, got %v", err)
+	}
+
+	if err = q.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueueCorruptedJob(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.db")
+
+	// Write a record which can't be decoded ahead of the jobs.
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte("pending"))
+		if err != nil {
+			return err
+		}
+		return bucket.Put(make([]byte, 8), []byte("not json"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var processed []string
+	handler := func(job *queue.Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		processed = append(processed, job.DeliveryID)
+		return nil
+	}
+	q, err := queue.New(path, handler, queue.Options{
+		Workers:      1,
+		MaxAttempts:  3,
+		Backoff:      time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+	}, log.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Start()
+
+	if err = q.Enqueue("pull_request", "working", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(processed) == 1
+	})
+
+	deadLetters, err := q.DeadLetters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deadLetters) != 1 || string(deadLetters[0].Payload) != "not json" || !deadLetters[0].Corrupted {
+		t.Fatalf("expected the corrupted record in the dead letters, got %v", deadLetters)
+	}
+	if err = q.RetryDeadLetter(deadLetters[0].ID); err != queue.ErrJobCorrupted {
+		t.Fatalf("expected the corrupted job not to be retried, got %v", err)
+	}
+
+	if err = q.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -7,11 +7,44 @@ import (
 	"github.com/google/go-github/v31/github"
 )
 
-// AboutThisBotCommands contains the message that links to the commands the bot understand.
-const AboutThisBotCommands = "I understand the commands that are listed [here](https://chewbacca.core.cloud.mattermost.com/command-help.html)"
+const (
+	// DefaultCommandHelpURL is the page listing the commands the bot understands.
+	DefaultCommandHelpURL = "https://chewbacca.core.cloud.mattermost.com/command-help.html"
+	// DefaultReleaseNoteProcessURL is the page describing the release note process.
+	DefaultReleaseNoteProcessURL = "https://github.com/mattermost/chewbacca#release-notes-process"
+)
+
+var (
+	commandHelpURL        = DefaultCommandHelpURL
+	releaseNoteProcessURL = DefaultReleaseNoteProcessURL
+)
+
+// SetLinks changes the pages linked in the bot responses, like for the GitHub Enterprise
+// instances which can't reach github.com. The empty URLs keep the defaults. It must be
+// called before handling the events.
+func SetLinks(commandHelp, releaseNoteProcess string) {
+	if commandHelp != "" {
+		commandHelpURL = commandHelp
+	}
+	if releaseNoteProcess != "" {
+		releaseNoteProcessURL = releaseNoteProcess
+	}
+}
+
+// CommandHelpURL returns the page listing the commands the bot understands.
+func CommandHelpURL() string {
+	return commandHelpURL
+}
 
-// AboutThisBot contains the text of both AboutThisBotWithoutCommands and AboutThisBotCommands.
-const AboutThisBot = AboutThisBotCommands
+// ReleaseNoteProcessURL returns the page describing the release note process.
+func ReleaseNoteProcessURL() string {
+	return releaseNoteProcessURL
+}
+
+// AboutThisBot returns the message that links to the commands the bot understands.
+func AboutThisBot() string {
+	return fmt.Sprintf("I understand the commands that are listed [here](%s)", commandHelpURL)
+}
 
 // FormatSimpleResponse formats a response that does not warrant additional explanation in the
 // details section.
@@ -23,7 +56,7 @@ func FormatSimpleResponse(to, message string) string {
 %s
 </details>`
 
-	return fmt.Sprintf(format, to, message, AboutThisBot)
+	return fmt.Sprintf(format, to, message, AboutThisBot())
 }
 
 // FormatICResponse nicely formats a response to an issue comment.
@@ -42,7 +75,7 @@ func FormatResponse(to, message, reason string) string {
 %s
 </details>`
 
-	return fmt.Sprintf(format, to, message, reason, AboutThisBot)
+	return fmt.Sprintf(format, to, message, reason, AboutThisBot())
 }
 
 // FormatResponseRaw nicely formats a response for one does not have an issue comment
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchGlob returns whether the slash separated name matches the glob pattern. The
+// pattern has the syntax of path.Match, and a `**` element matches any number of
+// directories, like in `vendor/**` or `**/*.pb.go`.
+func MatchGlob(pattern, name string) bool {
+	return matchGlobElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// MatchAnyGlob returns whether the name matches one of the glob patterns.
+func MatchAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGlobElems(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlobElems(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], name[0]); err != nil || !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -0,0 +1,33 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/chewbacca/internal/utils"
+)
+
+func TestMatchGlob(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"vendor/**", "vendor/github.com/pkg/errors/errors.go", true},
+		{"vendor/**", "server/vendor.go", false},
+		{"**/*.pb.go", "api.pb.go", true},
+		{"**/*.pb.go", "server/api/v1/api.pb.go", true},
+		{"**/*.pb.go", "server/api/v1/api.go", false},
+		{"server/**/mocks/*.go", "server/store/mocks/store.go", true},
+		{"server/**/mocks/*.go", "server/mocks/store.go", true},
+		{"server/**/mocks/*.go", "webapp/mocks/store.go", false},
+		{"go.sum", "go.sum", true},
+		{"go.sum", "server/go.sum", false},
+		{"*.go", "server/main.go", false},
+	}
+
+	for _, tc := range testCases {
+		if got := utils.MatchGlob(tc.pattern, tc.name); got != tc.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tc.pattern, tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/google/go-github/v31/github"
+)
+
+// WebhookPayload holds the fields shared by most of the webhook payloads. The
+// installation is only sent when running as a GitHub App.
+type WebhookPayload struct {
+	Action       string               `json:"action,omitempty"`
+	Installation *github.Installation `json:"installation,omitempty"`
+	Repository   *github.Repository   `json:"repository,omitempty"`
+}
+
+// WebhookPayloadFromJSON decodes the incomming message to a WebhookPayload
+func WebhookPayloadFromJSON(data io.Reader) *WebhookPayload {
+	decoder := json.NewDecoder(data)
+	var payload WebhookPayload
+	if err := decoder.Decode(&payload); err != nil {
+		return nil
+	}
+
+	return &payload
+}
+
+// InstallationEventFromJSON decodes the incomming message to a github.InstallationEvent
+func InstallationEventFromJSON(data io.Reader) *github.InstallationEvent {
+	decoder := json.NewDecoder(data)
+	var event github.InstallationEvent
+	if err := decoder.Decode(&event); err != nil {
+		return nil
+	}
+
+	return &event
+}
+
+// InstallationRepositoriesEventFromJSON decodes the incomming message to a github.InstallationRepositoriesEvent
+func InstallationRepositoriesEventFromJSON(data io.Reader) *github.InstallationRepositoriesEvent {
+	decoder := json.NewDecoder(data)
+	var event github.InstallationRepositoriesEvent
+	if err := decoder.Decode(&event); err != nil {
+		return nil
+	}
+
+	return &event
+}
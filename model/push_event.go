@@ -0,0 +1,19 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/google/go-github/v31/github"
+)
+
+// PushEventFromJSON decodes the incomming message to a github.PushEvent
+func PushEventFromJSON(data io.Reader) *github.PushEvent {
+	decoder := json.NewDecoder(data)
+	var event github.PushEvent
+	if err := decoder.Decode(&event); err != nil {
+		return nil
+	}
+
+	return &event
+}
@@ -33,3 +33,41 @@ const (
 	// IssueCommentActionDeleted means the comment was deleted.
 	IssueCommentActionDeleted = "deleted"
 )
+
+const (
+	// InstallationActionCreated means the app was installed.
+	InstallationActionCreated = "created"
+	// InstallationActionDeleted means the app was uninstalled.
+	InstallationActionDeleted = "deleted"
+	// InstallationActionSuspend means the app installation was suspended.
+	InstallationActionSuspend = "suspend"
+	// InstallationActionUnsuspend means the app installation was unsuspended.
+	InstallationActionUnsuspend = "unsuspend"
+
+	// CheckRunActionRerequested means the check run was re-run from the GitHub UI.
+	CheckRunActionRerequested = "rerequested"
+	// CheckRunActionRequestedAction means an action of the check run was requested.
+	CheckRunActionRequestedAction = "requested_action"
+
+	// InstallationRepositoriesActionAdded means repositories were added to the installation.
+	InstallationRepositoriesActionAdded = "added"
+	// InstallationRepositoriesActionRemoved means repositories were removed from the installation.
+	InstallationRepositoriesActionRemoved = "removed"
+)
+
+const (
+	// EventTypePing is sent when a webhook is created.
+	EventTypePing = "ping"
+	// EventTypePullRequest is sent when a pull request changes.
+	EventTypePullRequest = "pull_request"
+	// EventTypeIssueComment is sent when a comment on an issue or pull request changes.
+	EventTypeIssueComment = "issue_comment"
+	// EventTypeCheckRun is sent when a check run changes or a check run action is requested.
+	EventTypeCheckRun = "check_run"
+	// EventTypeInstallation is sent when the GitHub App installation changes.
+	EventTypeInstallation = "installation"
+	// EventTypePush is sent when commits are pushed to a branch or a tag.
+	EventTypePush = "push"
+	// EventTypeInstallationRepositories is sent when the repositories of a GitHub App installation change.
+	EventTypeInstallationRepositories = "installation_repositories"
+)